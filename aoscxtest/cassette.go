@@ -0,0 +1,335 @@
+package aoscxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a Replayer when the cassette holds no unused
+// interaction matching a request.
+var ErrNoInteraction = errors.New("no matching interaction in cassette")
+
+// redacted replaces secrets in recorded interactions.
+const redacted = "REDACTED"
+
+// Headers whose values are replaced by redacted when recording.
+var redactedHeaders = []string{"Authorization", "Cookie", "X-Csrf-Token"}
+
+// Form and query parameters whose values are replaced by redacted when recording.
+var redactedParams = []string{"password"}
+
+// Cassette is a sequence of recorded HTTP exchanges with a switch.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded form of an http.Request. URL holds the path
+// and query only, so a cassette can be replayed against any switch address.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded form of an http.Response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette written by Save.
+func LoadCassette(path string) (*Cassette, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cassette: %w", err)
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(contents, cassette); err != nil {
+		return nil, fmt.Errorf("unable to parse cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	contents, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// Recorder is an http.RoundTripper that forwards requests to Transport and
+// records every exchange, with credentials, session cookies and CSRF tokens
+// redacted. Use it as Client.Transport while running against a real switch,
+// then Save the cassette for replay.
+type Recorder struct {
+	// Transport performs the actual requests. When nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	// Redact, when set, is applied to each interaction after the default
+	// redaction, e.g. to remove hostnames or serial numbers from bodies.
+	Redact func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder sending requests through transport.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	return &Recorder{Transport: transport}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req_body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res_body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(res_body))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: req.Header.Clone(),
+			Body:   string(req_body),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			Header:     res.Header.Clone(),
+			Body:       string(res_body),
+		},
+	}
+	redactInteraction(&interaction)
+	if r.Redact != nil {
+		r.Redact(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the interactions recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// without any network access. Each request is answered by the first unused
+// interaction with the same method, path, query and body; JSON bodies are
+// compared by value and secrets are redacted before matching, as they were
+// when recording.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer serving the interactions of cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{cassette: cassette, used: make([]bool, len(cassette.Interactions))}
+}
+
+// NewReplayerFromFile loads the cassette at path and returns a Replayer for it.
+func NewReplayerFromFile(path string) (*Replayer, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette), nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := RecordedRequest{Method: req.Method, URL: req.URL.RequestURI(), Body: string(body)}
+	redactRequest(&wanted)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for index, interaction := range r.cassette.Interactions {
+		if r.used[index] || !matchRequest(interaction.Request, wanted) {
+			continue
+		}
+		r.used[index] = true
+
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        recorded.Status,
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, wanted.URL)
+}
+
+// Unused returns the interactions that have not been replayed yet, which lets a
+// test assert that the code under test made every recorded request.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for index, interaction := range r.cassette.Interactions {
+		if !r.used[index] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// readRequestBody returns the request body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	contents, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(contents))
+	return contents, nil
+}
+
+// matchRequest reports whether a recorded request matches a redacted live request.
+func matchRequest(recorded, wanted RecordedRequest) bool {
+	if recorded.Method != wanted.Method || recorded.URL != wanted.URL {
+		return false
+	}
+	if recorded.Body == wanted.Body {
+		return true
+	}
+	var recorded_json, wanted_json interface{}
+	if json.Unmarshal([]byte(recorded.Body), &recorded_json) != nil ||
+		json.Unmarshal([]byte(wanted.Body), &wanted_json) != nil {
+		return false
+	}
+	return reflect.DeepEqual(recorded_json, wanted_json)
+}
+
+// redactInteraction removes credentials, session cookies and CSRF tokens.
+func redactInteraction(interaction *Interaction) {
+	redactRequest(&interaction.Request)
+	for _, header := range redactedHeaders {
+		if interaction.Request.Header.Get(header) != "" {
+			interaction.Request.Header.Set(header, redacted)
+		}
+		if interaction.Response.Header.Get(header) != "" {
+			interaction.Response.Header.Set(header, redacted)
+		}
+	}
+	cookies := interaction.Response.Header.Values("Set-Cookie")
+	for index, cookie := range cookies {
+		cookies[index] = redactCookie(cookie)
+	}
+}
+
+// redactRequest redacts secrets in the URL query and form-encoded body of a request.
+func redactRequest(req *RecordedRequest) {
+	if path, query, found := strings.Cut(req.URL, "?"); found {
+		req.URL = path + "?" + redactParams(query)
+	}
+	if req.Body != "" && !strings.HasPrefix(strings.TrimSpace(req.Body), "{") {
+		req.Body = redactParams(req.Body)
+	}
+}
+
+// redactParams replaces the values of redactedParams in a URL-encoded string,
+// leaving it unchanged when it is not URL-encoded.
+func redactParams(encoded string) string {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return encoded
+	}
+	changed := false
+	for _, param := range redactedParams {
+		if _, ok := values[param]; ok {
+			values.Set(param, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return encoded
+	}
+	return values.Encode()
+}
+
+// redactCookie replaces the value of a Set-Cookie header, keeping its attributes.
+func redactCookie(cookie string) string {
+	pair, attributes, _ := strings.Cut(cookie, ";")
+	name, _, _ := strings.Cut(pair, "=")
+	redacted_cookie := name + "=" + redacted
+	if attributes != "" {
+		redacted_cookie += ";" + attributes
+	}
+	return redacted_cookie
+}
+
+// String summarizes the interaction for test failure messages.
+func (i Interaction) String() string {
+	return i.Request.Method + " " + i.Request.URL + " -> " + strconv.Itoa(i.Response.StatusCode)
+}
@@ -0,0 +1,114 @@
+package aoscxtest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// exercise runs a fixed sequence of operations and returns the LAG read back.
+func exercise(t *testing.T, c *aoscxgo.Client) aoscxgo.LagInterface {
+	t.Helper()
+	sw, err := aoscxgo.Connect(c)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	vlan := aoscxgo.Vlan{VlanId: 200, Name: "uplink"}
+	if err := vlan.Create(sw); err != nil {
+		t.Fatalf("Vlan.Create: %v", err)
+	}
+	lag := aoscxgo.LagInterface{Name: "lag60", AdminState: "up", Description: "uplink VLAN", LacpMode: "active", VlanTag: 200}
+	if err := lag.Create(sw); err != nil {
+		t.Fatalf("LagInterface.Create: %v", err)
+	}
+	got := aoscxgo.LagInterface{Name: "lag60"}
+	if err := got.Get(sw); err != nil {
+		t.Fatalf("LagInterface.Get: %v", err)
+	}
+	if err := sw.Logout(); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	return got
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	defer srv.Close()
+	srv.SetCredentials("automation", "s3cret-password")
+
+	client := srv.Client()
+	recorder := aoscxtest.NewRecorder(client.Transport)
+	client.Transport = recorder
+
+	recorded := exercise(t, client)
+
+	path := filepath.Join(t.TempDir(), "lag.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(contents), "s3cret-password") {
+		t.Error("cassette contains the password")
+	}
+	for _, interaction := range recorder.Cassette().Interactions {
+		for _, cookie := range interaction.Response.Header.Values("Set-Cookie") {
+			if !strings.HasPrefix(cookie, "id=REDACTED") {
+				t.Errorf("cassette contains session cookie %q", cookie)
+			}
+		}
+		if csrf := interaction.Response.Header.Get("X-Csrf-Token"); csrf != "" && csrf != "REDACTED" {
+			t.Errorf("cassette contains CSRF token %q", csrf)
+		}
+	}
+
+	// Replay against an address that does not exist, with the real password
+	replayer, err := aoscxtest.NewReplayerFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline := &aoscxgo.Client{
+		Hostname:  "switch.invalid",
+		Username:  "automation",
+		Password:  "s3cret-password",
+		Transport: replayer,
+	}
+	replayed := exercise(t, offline)
+
+	if replayed.Description != recorded.Description || replayed.LacpMode != recorded.LacpMode ||
+		replayed.VlanTag != recorded.VlanTag || replayed.VlanMode != recorded.VlanMode {
+		t.Errorf("replayed LAG = %+v, recorded %+v", replayed, recorded)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("interactions not replayed: %v", unused)
+	}
+}
+
+func TestReplayerNoInteraction(t *testing.T) {
+	replayer := aoscxtest.NewReplayer(&aoscxtest.Cassette{Interactions: []aoscxtest.Interaction{{
+		Request:  aoscxtest.RecordedRequest{Method: "GET", URL: "/rest/v10.09/system/vlans/1"},
+		Response: aoscxtest.RecordedResponse{StatusCode: 200, Status: "200 OK", Body: `{"id": 1}`},
+	}}})
+
+	sw := &aoscxgo.Client{Hostname: "switch.invalid", Version: "v10.09", Transport: replayer}
+
+	vlan := aoscxgo.Vlan{VlanId: 2}
+	if err := vlan.Get(sw); !errors.Is(err, aoscxtest.ErrNoInteraction) {
+		t.Errorf("Get() unrecorded VLAN error = %v, want ErrNoInteraction", err)
+	}
+	if err := (&aoscxgo.Vlan{VlanId: 1}).Get(sw); err != nil {
+		t.Errorf("Get() recorded VLAN error = %v", err)
+	}
+	if err := (&aoscxgo.Vlan{VlanId: 1}).Get(sw); !errors.Is(err, aoscxtest.ErrNoInteraction) {
+		t.Errorf("Get() replayed twice error = %v, want ErrNoInteraction", err)
+	}
+}
@@ -0,0 +1,412 @@
+package aoscxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readOnly lists the attributes of each table that are omitted by ?selector=writable
+// and preserved by PUT.
+var readOnly = map[string][]string{
+	"vlans":      {"id"},
+	"interfaces": {"name", "type"},
+}
+
+// handleVlans serves system/vlans and system/vlans/{id}.
+func (s *Server) handleVlans(w http.ResponseWriter, r *http.Request, version string, path []string, body []byte) {
+	if len(path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			collection := map[string]map[string]interface{}{}
+			for id, vlan := range s.vlans {
+				collection[strconv.Itoa(id)] = vlan
+			}
+			writeCollection(w, r, version, "system/vlans", "vlans", collection)
+		case http.MethodPost:
+			attributes, ok := decodeAttributes(w, body)
+			if !ok {
+				return
+			}
+			id, ok := attributes["id"].(float64)
+			if !ok || id < 1 || id > 4094 {
+				writeError(w, http.StatusBadRequest, "Invalid or missing attribute: id")
+				return
+			}
+			if _, exists := s.vlans[int(id)]; exists {
+				writeError(w, http.StatusBadRequest, "Object already exists")
+				return
+			}
+			s.vlans[int(id)] = mergeAttributes(map[string]interface{}{}, attributes)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(path[0])
+	vlan, exists := s.vlans[id]
+	if err != nil || len(path) > 1 || !exists {
+		writeError(w, http.StatusNotFound, "Object not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeObject(w, r, "vlans", vlan)
+	case http.MethodPut, http.MethodPatch:
+		attributes, ok := decodeAttributes(w, body)
+		if !ok {
+			return
+		}
+		s.vlans[id] = updateAttributes(r.Method, "vlans", vlan, attributes)
+		writeUpdated(w, r.Method)
+	case http.MethodDelete:
+		delete(s.vlans, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// handleInterfaces serves system/interfaces, system/interfaces/{name} and the
+// ip6_addresses sub-collection of an interface.
+func (s *Server) handleInterfaces(w http.ResponseWriter, r *http.Request, version string, path []string, body []byte) {
+	if len(path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeCollection(w, r, version, "system/interfaces", "interfaces", s.interfaces)
+		case http.MethodPost:
+			attributes, ok := decodeAttributes(w, body)
+			if !ok {
+				return
+			}
+			if !checkVlanMode(w, attributes) {
+				return
+			}
+			name, _ := attributes["name"].(string)
+			if name == "" {
+				writeError(w, http.StatusBadRequest, "Invalid or missing attribute: name")
+				return
+			}
+			if _, exists := s.interfaces[name]; exists {
+				writeError(w, http.StatusBadRequest, "Object already exists")
+				return
+			}
+			iface := mergeAttributes(map[string]interface{}{}, attributes)
+			if _, ok := iface["type"]; !ok {
+				iface["type"] = "system"
+			}
+			s.interfaces[name] = iface
+			w.WriteHeader(http.StatusCreated)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+		return
+	}
+
+	name := path[0]
+	iface, exists := s.interfaces[name]
+	if !exists {
+		writeError(w, http.StatusNotFound, "Object not found")
+		return
+	}
+	if len(path) > 1 {
+		if path[1] != "ip6_addresses" || len(path) > 3 {
+			writeError(w, http.StatusNotFound, "Object not found")
+			return
+		}
+		s.handleIP6Addresses(w, r, version, name, path[2:], body)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeObject(w, r, "interfaces", iface)
+	case http.MethodPut, http.MethodPatch:
+		attributes, ok := decodeAttributes(w, body)
+		if !ok || !checkVlanMode(w, attributes) {
+			return
+		}
+		s.interfaces[name] = updateAttributes(r.Method, "interfaces", iface, attributes)
+		writeUpdated(w, r.Method)
+	case http.MethodDelete:
+		if iface["type"] == "system" {
+			writeError(w, http.StatusBadRequest, "Physical interfaces cannot be deleted")
+			return
+		}
+		delete(s.interfaces, name)
+		delete(s.ip6Addresses, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// handleIP6Addresses serves system/interfaces/{name}/ip6_addresses and its entries.
+func (s *Server) handleIP6Addresses(w http.ResponseWriter, r *http.Request, version string, name string, path []string, body []byte) {
+	addresses := s.ip6Addresses[name]
+	collection_uri := "system/interfaces/" + url.PathEscape(name) + "/ip6_addresses"
+
+	if len(path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeCollection(w, r, version, collection_uri, "ip6_addresses", addresses)
+		case http.MethodPost:
+			attributes, ok := decodeAttributes(w, body)
+			if !ok {
+				return
+			}
+			address, _ := attributes["address"].(string)
+			if address == "" {
+				writeError(w, http.StatusBadRequest, "Invalid or missing attribute: address")
+				return
+			}
+			if _, exists := addresses[address]; exists {
+				writeError(w, http.StatusBadRequest, "Object already exists")
+				return
+			}
+			if addresses == nil {
+				addresses = map[string]map[string]interface{}{}
+				s.ip6Addresses[name] = addresses
+			}
+			addresses[address] = mergeAttributes(map[string]interface{}{}, attributes)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+		return
+	}
+
+	address, exists := addresses[path[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "Object not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeObject(w, r, "ip6_addresses", address)
+	case http.MethodDelete:
+		delete(addresses, path[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// handleRunningConfig serves configs/running-config. GET returns the running
+// configuration as text, POST with ?dryrun=validate or ?dryrun=apply starts a
+// dryrun and GET with ?dryrun returns its result.
+func (s *Server) handleRunningConfig(w http.ResponseWriter, r *http.Request, body []byte) {
+	query := r.URL.Query()
+	_, dryrun := query["dryrun"]
+
+	switch {
+	case r.Method == http.MethodGet && dryrun:
+		if s.dryrun == nil {
+			writeError(w, http.StatusNotFound, "No dryrun request found")
+			return
+		}
+		if s.pendingPolls != 0 {
+			if s.pendingPolls > 0 {
+				s.pendingPolls--
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"state": "pending"})
+			return
+		}
+		writeJSON(w, http.StatusOK, s.dryrun)
+	case r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(s.runningConfig))
+	case r.Method == http.MethodPost && dryrun:
+		mode := query.Get("dryrun")
+		if mode != "validate" && mode != "apply" {
+			writeError(w, http.StatusBadRequest, "Invalid dryrun mode: "+mode)
+			return
+		}
+		config := string(body)
+		s.pendingPolls = s.dryrunPending
+		config_errors := s.validate(config)
+		if len(config_errors) > 0 {
+			s.dryrun = map[string]interface{}{"state": "error", "errors": config_errors}
+		} else {
+			s.dryrun = map[string]interface{}{"state": "success", "errors": []ConfigError{}}
+			if mode == "apply" {
+				s.runningConfig = config
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// decodeAttributes decodes a JSON object request body, writing a 400 response on failure.
+func decodeAttributes(w http.ResponseWriter, body []byte) (map[string]interface{}, bool) {
+	attributes := map[string]interface{}{}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return attributes, true
+	}
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		writeError(w, http.StatusBadRequest, "Malformed JSON: "+err.Error())
+		return nil, false
+	}
+	return attributes, true
+}
+
+// checkVlanMode rejects a vlan_mode the switch does not accept, such as "trunk",
+// writing a 400 response.
+func checkVlanMode(w http.ResponseWriter, attributes map[string]interface{}) bool {
+	switch attributes["vlan_mode"] {
+	case nil, "access", "native-untagged", "native-tagged":
+		return true
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid value for attribute vlan_mode: %v", attributes["vlan_mode"]))
+	return false
+}
+
+// mergeAttributes writes attributes into object the way the switch stores them:
+// null values unset the attribute and references given as URIs are returned as
+// maps from key to URI.
+func mergeAttributes(object, attributes map[string]interface{}) map[string]interface{} {
+	for key, value := range attributes {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = normalizeReference(value)
+	}
+	return object
+}
+
+// updateAttributes applies a PATCH, which merges the attributes, or a PUT, which
+// replaces every writable attribute, to a stored object.
+func updateAttributes(method, table string, object, attributes map[string]interface{}) map[string]interface{} {
+	if method == http.MethodPatch {
+		return mergeAttributes(object, attributes)
+	}
+	replaced := map[string]interface{}{}
+	for _, key := range readOnly[table] {
+		if value, ok := object[key]; ok {
+			replaced[key] = value
+		}
+	}
+	for _, key := range readOnly[table] {
+		delete(attributes, key)
+	}
+	return mergeAttributes(replaced, attributes)
+}
+
+// normalizeReference converts a URI, or a list of URIs, into the map from key to
+// URI the switch returns for references.
+func normalizeReference(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		if key, ok := referenceKey(typed); ok {
+			return map[string]interface{}{key: typed}
+		}
+	case []interface{}:
+		references := map[string]interface{}{}
+		for _, item := range typed {
+			uri, _ := item.(string)
+			key, ok := referenceKey(uri)
+			if !ok {
+				return value
+			}
+			references[key] = uri
+		}
+		if len(references) > 0 {
+			return references
+		}
+	}
+	return value
+}
+
+// referenceKey returns the unescaped last segment of a REST URI.
+func referenceKey(uri string) (string, bool) {
+	if !strings.HasPrefix(uri, "/rest/v") {
+		return "", false
+	}
+	key, err := url.PathUnescape(uri[strings.LastIndex(uri, "/")+1:])
+	return key, err == nil && key != ""
+}
+
+// writeObject writes a single object, honouring ?selector=writable and ?attributes.
+func writeObject(w http.ResponseWriter, r *http.Request, table string, object map[string]interface{}) {
+	writeJSON(w, http.StatusOK, selectAttributes(r.URL.Query(), table, object))
+}
+
+// writeCollection writes a collection as a map from key to URI, or from key to
+// object when ?depth is one or more. Objects not matching ?filter are left out.
+func writeCollection(w http.ResponseWriter, r *http.Request, version, collection_uri, table string, objects map[string]map[string]interface{}) {
+	query := r.URL.Query()
+	depth, _ := strconv.Atoi(query.Get("depth"))
+
+	response := map[string]interface{}{}
+	for key, object := range objects {
+		if !matchesFilter(query.Get("filter"), object) {
+			continue
+		}
+		if depth > 0 {
+			response[key] = selectAttributes(query, table, object)
+		} else {
+			response[key] = "/rest/" + version + "/" + collection_uri + "/" + url.PathEscape(key)
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// matchesFilter reports whether object has every attribute value listed in
+// filter, which has the form "type:lag,admin:up".
+func matchesFilter(filter string, object map[string]interface{}) bool {
+	if filter == "" {
+		return true
+	}
+	for _, condition := range strings.Split(filter, ",") {
+		attribute, value, _ := strings.Cut(condition, ":")
+		actual, ok := object[attribute]
+		if !ok || fmt.Sprint(actual) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// selectAttributes applies the selector and attributes query parameters to object.
+func selectAttributes(query url.Values, table string, object map[string]interface{}) map[string]interface{} {
+	selected := copyAttributes(object)
+	if selected == nil {
+		selected = map[string]interface{}{}
+	}
+	if query.Get("selector") == "writable" {
+		for _, key := range readOnly[table] {
+			delete(selected, key)
+		}
+	}
+	if attributes := query.Get("attributes"); attributes != "" {
+		wanted := strings.Split(attributes, ",")
+		sort.Strings(wanted)
+		for key := range selected {
+			index := sort.SearchStrings(wanted, key)
+			if index == len(wanted) || wanted[index] != key {
+				delete(selected, key)
+			}
+		}
+	}
+	return selected
+}
+
+// writeUpdated writes the success status the switch uses for PUT and PATCH.
+func writeUpdated(w http.ResponseWriter, method string) {
+	if method == http.MethodPut {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,457 @@
+/*
+Package aoscxtest provides an in-memory fake AOS-CX REST server for tests.
+
+The server implements REST version discovery, login and logout with cookie and
+CSRF token, the system/vlans and system/interfaces tables (including
+?depth, ?filter, ?selector=writable and ip6_addresses) and the
+configs/running-config dryrun flow, keeping all state in memory. Like the
+switch, it rejects a vlan_mode other than access, native-untagged and
+native-tagged:
+
+	srv := aoscxtest.NewServer()
+	defer srv.Close()
+
+	sw, err := aoscxgo.Connect(srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink"}
+	err = vlan.Create(sw)
+
+It also provides a Recorder, which captures exchanges with a real switch into a
+Cassette with secrets redacted, and a Replayer, which serves a saved Cassette
+back offline. Both are http.RoundTripper implementations for Client.Transport.
+*/
+package aoscxtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// Default settings of a new Server.
+const (
+	DefaultUsername = "admin"
+	DefaultPassword = "admin"
+	DefaultVersion  = "v10.09"
+)
+
+// sessionCookie is the name of the session cookie set by login.
+const sessionCookie = "id"
+
+// Request records a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// ConfigError is a dryrun error reported for a line of a submitted configuration.
+type ConfigError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Server is an in-memory fake AOS-CX switch serving the REST API over TLS.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	// Credentials accepted by login.
+	username string
+	password string
+	// REST versions offered by version discovery, newest last.
+	versions []string
+	// maxSessions limits concurrent sessions, zero means unlimited.
+	maxSessions int
+
+	// sessions maps session cookie values to their CSRF token.
+	sessions map[string]string
+
+	vlans         map[int]map[string]interface{}
+	interfaces    map[string]map[string]interface{}
+	ip6Addresses  map[string]map[string]map[string]interface{}
+	runningConfig string
+	validate      func(config string) []ConfigError
+	dryrun        map[string]interface{}
+	// dryrunPending is the number of polls a new dryrun reports as pending,
+	// pendingPolls the number left for the current one; negative is forever.
+	dryrunPending int
+	pendingPolls  int
+
+	requests []Request
+}
+
+// NewServer starts a fake switch with VLAN 1 and the physical interfaces 1/1/1
+// through 1/1/8. The caller must Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		username:     DefaultUsername,
+		password:     DefaultPassword,
+		versions:     []string{DefaultVersion},
+		sessions:     map[string]string{},
+		vlans:        map[int]map[string]interface{}{},
+		interfaces:   map[string]map[string]interface{}{},
+		ip6Addresses: map[string]map[string]map[string]interface{}{},
+		validate:     func(string) []ConfigError { return nil },
+	}
+	s.vlans[1] = map[string]interface{}{
+		"id":    float64(1),
+		"name":  "DEFAULT_VLAN_1",
+		"type":  "default",
+		"admin": "up",
+	}
+	for port := 1; port <= 8; port++ {
+		s.AddInterface("1/1/" + strconv.Itoa(port))
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an unconnected aoscxgo.Client configured to reach the server
+// with the default credentials and trust its certificate.
+func (s *Server) Client() *aoscxgo.Client {
+	address, _ := url.Parse(s.URL)
+	host, port_str, _ := net.SplitHostPort(address.Host)
+	port, _ := strconv.Atoi(port_str)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &aoscxgo.Client{
+		Hostname:  host,
+		Port:      port,
+		Username:  s.username,
+		Password:  s.password,
+		Transport: s.Server.Client().Transport.(*http.Transport).Clone(),
+	}
+}
+
+// SetCredentials changes the username and password accepted by login.
+func (s *Server) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+	s.password = password
+}
+
+// SetVersions changes the REST versions offered by version discovery.
+// The last version is reported as the latest one.
+func (s *Server) SetVersions(versions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions = versions
+}
+
+// SetMaxSessions limits the number of concurrent sessions; further logins fail
+// with a session limit error. Zero means unlimited.
+func (s *Server) SetMaxSessions(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxSessions = limit
+}
+
+// SetConfigValidator sets the function used by dryrun to validate a configuration.
+// By default every configuration is accepted.
+func (s *Server) SetConfigValidator(validate func(config string) []ConfigError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validate = validate
+}
+
+// SetDryRunPending makes every following dryrun report the "pending" state for
+// the given number of polls before its result, as a switch does while it checks
+// a large configuration. A negative number keeps the dryrun pending forever.
+func (s *Server) SetDryRunPending(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dryrunPending = polls
+}
+
+// ExpireSessions invalidates every session, as a switch does on session timeout.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]string{}
+}
+
+// Sessions returns the number of open sessions.
+func (s *Server) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// AddInterface adds a physical interface with default configuration.
+func (s *Server) AddInterface(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interfaces[name] = map[string]interface{}{
+		"name":  name,
+		"type":  "system",
+		"admin": "down",
+	}
+}
+
+// Vlan returns a copy of the stored attributes of a VLAN.
+func (s *Server) Vlan(id int) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vlan, ok := s.vlans[id]
+	return copyAttributes(vlan), ok
+}
+
+// Interface returns a copy of the stored attributes of an interface.
+func (s *Server) Interface(name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	iface, ok := s.interfaces[name]
+	return copyAttributes(iface), ok
+}
+
+// IP6Addresses returns the IPv6 addresses configured on an interface.
+func (s *Server) IP6Addresses(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var addresses []string
+	for address := range s.ip6Addresses[name] {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// RunningConfig returns the running configuration.
+func (s *Server) RunningConfig() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.runningConfig
+}
+
+// SetRunningConfig replaces the running configuration.
+func (s *Server) SetRunningConfig(config string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runningConfig = config
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests clears the recorded requests.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// serveHTTP records the request and dispatches it to the matching handler.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   string(body),
+	})
+
+	segments := splitPath(r.URL.EscapedPath())
+	if len(segments) == 0 || segments[0] != "rest" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if len(segments) == 1 {
+		s.handleVersions(w, r)
+		return
+	}
+
+	version := segments[1]
+	if !s.offersVersion(version) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	resource := segments[2:]
+	switch {
+	case len(resource) == 1 && resource[0] == "login":
+		s.handleLogin(w, r, body)
+		return
+	case len(resource) == 1 && resource[0] == "logout":
+		s.handleLogout(w, r)
+		return
+	}
+
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch {
+	case len(resource) >= 2 && resource[0] == "system" && resource[1] == "vlans":
+		s.handleVlans(w, r, version, resource[2:], body)
+	case len(resource) >= 2 && resource[0] == "system" && resource[1] == "interfaces":
+		s.handleInterfaces(w, r, version, resource[2:], body)
+	case len(resource) == 2 && resource[0] == "configs" && resource[1] == "running-config":
+		s.handleRunningConfig(w, r, body)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// offersVersion reports whether version is one of the served REST versions.
+func (s *Server) offersVersion(version string) bool {
+	for _, offered := range s.versions {
+		if offered == version {
+			return true
+		}
+	}
+	return false
+}
+
+// handleVersions serves GET /rest.
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	response := map[string]interface{}{}
+	for _, version := range s.versions {
+		response[version] = versionInfo(version)
+	}
+	if len(s.versions) > 0 {
+		response["latest"] = versionInfo(s.versions[len(s.versions)-1])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// versionInfo describes a REST version the way GET /rest does.
+func versionInfo(version string) map[string]interface{} {
+	return map[string]interface{}{
+		"version": version,
+		"prefix":  "/rest/" + version,
+		"doc":     "/api/" + version,
+	}
+}
+
+// handleLogin serves POST /rest/{version}/login, accepting the credentials as a
+// form-encoded body or in the query string.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request, body []byte) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	credentials := r.URL.Query()
+	if len(body) > 0 {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Bad Request")
+			return
+		}
+		credentials = form
+	}
+	if credentials.Get("username") != s.username || credentials.Get("password") != s.password {
+		writeError(w, http.StatusUnauthorized, "Login failed")
+		return
+	}
+	if s.maxSessions > 0 && len(s.sessions) >= s.maxSessions {
+		writeError(w, http.StatusUnauthorized, "Login failed: session limit reached")
+		return
+	}
+
+	session, csrf := randomToken(), randomToken()
+	s.sessions[session] = csrf
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/", Secure: true, HttpOnly: true})
+	w.Header().Set("X-Csrf-Token", csrf)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleLogout serves POST /rest/{version}/logout.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	cookie, _ := r.Cookie(sessionCookie)
+	delete(s.sessions, cookie.Value)
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticated reports whether r carries a valid session cookie and CSRF token.
+func (s *Server) authenticated(r *http.Request) bool {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return false
+	}
+	csrf, ok := s.sessions[cookie.Value]
+	return ok && r.Header.Get("X-Csrf-Token") == csrf
+}
+
+// randomToken returns a random hex token.
+func randomToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// splitPath splits an escaped URL path into unescaped segments.
+func splitPath(escaped string) []string {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(escaped, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		segments = append(segments, unescaped)
+	}
+	return segments
+}
+
+// writeJSON writes value as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a plain text error response like the switch does.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	io.WriteString(w, message)
+}
+
+// copyAttributes returns a deep copy of a stored object.
+func copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	encoded, _ := json.Marshal(attributes)
+	var copied map[string]interface{}
+	json.Unmarshal(encoded, &copied)
+	return copied
+}
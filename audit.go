@@ -0,0 +1,205 @@
+package aoscxgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEvent records a configuration change sent to the switch.
+type AuditEvent struct {
+	Time     time.Time `json:"timestamp"`
+	Hostname string    `json:"hostname"`
+	Username string    `json:"username"`
+	// ResourceType is the kind of resource that was changed, e.g. "Vlan" or
+	// "L2Interface", and ResourceKey its key, e.g. "100" or "1/1/1". Requests
+	// sent with Client.Post, Put, Patch and Delete report the table instead,
+	// and the key of the row when the URL names one.
+	ResourceType string `json:"resource_type"`
+	ResourceKey  string `json:"resource_key,omitempty"`
+	// Table is the table the request was sent to, e.g. "system/vlans" or
+	// "system/interfaces/ip6_addresses".
+	Table string `json:"table"`
+	// Operation is the resource method that made the change: "create",
+	// "update", "replace", "delete" or, for a running configuration applied
+	// by FullConfig, "apply". A Delete of a port is sent as a PUT, so Method
+	// may differ. Client.Post, Put, Patch and Delete report the operation
+	// of their HTTP method.
+	Operation   string          `json:"operation"`
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	// StatusCode is the HTTP status of the response, zero when no response was received.
+	StatusCode int           `json:"status_code"`
+	Duration   time.Duration `json:"duration_ns"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// AuditSink receives an AuditEvent for every POST, PUT, PATCH and DELETE sent
+// to the switch, after the response has been received. Operations captured by
+// DryRun are not sent and therefore not audited. Audit is called synchronously
+// and may be called from several goroutines at once.
+type AuditSink interface {
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// FileAuditSink is an AuditSink appending events to a file as JSON lines.
+// Every event is synced to disk before the operation returns.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink opens path for appending, creating it with mode 0600 if needed.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+// Audit implements AuditSink
+func (f *FileAuditSink) Audit(ctx context.Context, event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close closes the audit log file.
+func (f *FileAuditSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// audit reports a mutating request and its outcome to the AuditSink, if any.
+// A failing sink is logged rather than failing an operation the switch has
+// already carried out.
+func (c *Client) audit(req *http.Request, res *http.Response, req_err error, start time.Time) {
+	if c.AuditSink == nil {
+		return
+	}
+
+	table, row_key := resourceTable(req.URL)
+	event := AuditEvent{
+		Time:         start,
+		Hostname:     c.Hostname,
+		Username:     c.sessionUsername(),
+		ResourceType: table,
+		ResourceKey:  row_key,
+		Table:        table,
+		Operation:    auditOperation(req),
+		Method:       req.Method,
+		URL:          req.URL.Redacted(),
+		Duration:     time.Since(start),
+	}
+	if target, ok := req.Context().Value(auditTargetKey{}).(auditTarget); ok {
+		event.ResourceType = target.kind
+		event.ResourceKey = target.key
+		event.Operation = target.operation
+	}
+	event.RequestBody, _ = requestBody(req)
+	if res != nil {
+		event.StatusCode = res.StatusCode
+	}
+	if req_err != nil {
+		event.Error = req_err.Error()
+	}
+
+	if err := c.AuditSink.Audit(req.Context(), event); err != nil {
+		c.logger().Error("failed to record audit event",
+			"method", req.Method, "path", req.URL.Path, "error", err)
+	}
+}
+
+// auditTargetKey is the context key of the auditTarget set by resource methods.
+type auditTargetKey struct{}
+
+// auditTarget is the operation and resource a resource method is changing.
+type auditTarget struct {
+	operation string
+	kind      string
+	key       string
+}
+
+// withAuditTarget returns a copy of ctx under which every mutating request is
+// audited as operation on the resource of the given kind and key, rather than
+// from its HTTP method and URL. A resource method calling another one, such as
+// L2Interface.Create creating its VLAN, audits the requests of the inner call
+// as that resource.
+func withAuditTarget(ctx context.Context, operation, kind, key string) context.Context {
+	return context.WithValue(ctx, auditTargetKey{}, auditTarget{operation: operation, kind: kind, key: key})
+}
+
+// auditOperation names the change made by a mutating request sent without an
+// auditTarget.
+func auditOperation(req *http.Request) string {
+	switch req.Method {
+	case http.MethodPost:
+		if req.URL.Query().Has("dryrun") {
+			return "apply"
+		}
+		return "create"
+	case http.MethodPut:
+		return "replace"
+	case http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(req.Method)
+}
+
+// resourceTable derives the table and row key from a REST URL. Below "system",
+// AOS-CX paths alternate between table names and row keys, e.g.
+// /rest/v10.09/system/interfaces/1%2F1%2F1/ip6_addresses/<address>. Anything
+// before the "rest" segment followed by the API version, such as a BasePath,
+// is skipped.
+func resourceTable(u *url.URL) (string, string) {
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for index, segment := range segments {
+		if segment == "rest" && index+1 < len(segments) && isAPIVersion(segments[index+1]) {
+			segments = segments[index+2:]
+			break
+		}
+	}
+	if len(segments) == 0 || segments[0] != "system" {
+		return strings.Join(segments, "/"), ""
+	}
+
+	tables := []string{"system"}
+	key := ""
+	for index := 1; index < len(segments); index++ {
+		if index%2 == 1 {
+			tables = append(tables, segments[index])
+			key = ""
+		} else if unescaped, err := url.PathUnescape(segments[index]); err == nil {
+			key = unescaped
+		}
+	}
+	return strings.Join(tables, "/"), key
+}
+
+// isAPIVersion reports whether a URL path segment is a REST API version.
+func isAPIVersion(segment string) bool {
+	if segment == "latest" {
+		return true
+	}
+	_, err := ParseAPIVersion(segment)
+	return err == nil
+}
@@ -0,0 +1,268 @@
+package aoscxgo_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// readAuditLog returns the events written to a JSON-lines audit log.
+func readAuditLog(t *testing.T, path string) []aoscxgo.AuditEvent {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var events []aoscxgo.AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event aoscxgo.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("audit line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestResourceTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantTable string
+		wantKey   string
+	}{
+		{"table", "https://10.0.0.1/rest/v10.09/system/vlans", "system/vlans", ""},
+		{"row", "https://10.0.0.1/rest/v10.09/system/vlans/100", "system/vlans", "100"},
+		{"escaped key", "https://10.0.0.1/rest/v10.09/system/interfaces/1%2F1%2F1", "system/interfaces", "1/1/1"},
+		{"nested row", "https://10.0.0.1/rest/v10.09/system/interfaces/vlan100/ip6_addresses/2001:db8::1%2F64", "system/interfaces/ip6_addresses", "2001:db8::1/64"},
+		{"outside system", "https://10.0.0.1/rest/v10.09/configs/running-config", "configs/running-config", ""},
+		{"latest", "https://10.0.0.1/rest/latest/system/vlans/1", "system/vlans", "1"},
+		{"BasePath", "https://proxy/switches/core1/rest/v10.09/system/vlans/100", "system/vlans", "100"},
+		{"BasePath containing rest", "https://proxy/rest/core1/rest/v10.09/system/interfaces/1%2F1%2F1", "system/interfaces", "1/1/1"},
+		{"query", "https://proxy/switches/core1/rest/v10.09/system/vlans?depth=1", "system/vlans", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			table, key := aoscxgo.ResourceTable(u)
+			if table != tt.wantTable || key != tt.wantKey {
+				t.Errorf("resourceTable(%s) = %q, %q, want %q, %q", tt.url, table, key, tt.wantTable, tt.wantKey)
+			}
+		})
+	}
+}
+
+// proxyClient returns a client reaching srv through a reverse proxy that
+// serves the switch below base_path.
+func proxyClient(t *testing.T, srv *aoscxtest.Server, base_path string) *aoscxgo.Client {
+	t.Helper()
+	client := srv.Client()
+	client.BasePath = base_path
+	transport := client.Transport
+	client.Transport = aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasPrefix(req.URL.Path, base_path+"/rest") {
+			t.Errorf("request to %s is not below %s", req.URL.Path, base_path)
+		}
+		req = req.Clone(req.Context())
+		req.URL.Path = strings.TrimPrefix(req.URL.Path, base_path)
+		req.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, base_path)
+		return transport.RoundTrip(req)
+	})
+	return client
+}
+
+func TestAuditBasePath(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	sw, err := aoscxgo.Connect(proxyClient(t, srv, "/switches/core1"))
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := aoscxgo.NewFileAuditSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sw.AuditSink = sink
+
+	l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1", AdminState: "up"}, VlanMode: "access", VlanTag: 1}
+	if err := l2.Create(sw); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	events := readAuditLog(t, path)
+	if len(events) == 0 {
+		t.Fatal("no audit events")
+	}
+	for _, event := range events {
+		if event.Table != "system/interfaces" || event.ResourceKey != "1/1/1" {
+			t.Errorf("event %s %s = table %q, key %q, want system/interfaces, 1/1/1", event.Method, event.URL, event.Table, event.ResourceKey)
+		}
+	}
+
+	// Capability checks see the table below the BasePath
+	srv.SetVersions("v10.04")
+	old, err := aoscxgo.Connect(proxyClient(t, srv, "/switches/core1"))
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	lag := aoscxgo.LagInterface{Name: "lag10", AdminState: "up"}
+	var capability_err *aoscxgo.CapabilityError
+	if err := lag.Create(old); !errors.As(err, &capability_err) || capability_err.Capability != aoscxgo.CapabilityLagInterfaces {
+		t.Errorf("Create() on v10.04 error = %v, want %s to be unsupported", err, aoscxgo.CapabilityLagInterfaces)
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := aoscxgo.NewFileAuditSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sw.AuditSink = sink
+
+	vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink"}
+	if err := vlan.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := vlan.Get(sw); err != nil {
+		t.Fatal(err)
+	}
+	lag := aoscxgo.LagInterface{Name: "lag1", AdminState: "down"}
+	if err := lag.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	lag.Description = "uplink"
+	if err := lag.Update(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := vlan.Delete(sw); err != nil {
+		t.Fatal(err)
+	}
+	// Changes the switch answers with an error are audited too
+	if err := vlan.Delete(sw); err != nil {
+		t.Fatal(err)
+	}
+	// A port is deleted by a PUT resetting it
+	l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1"}}
+	if err := l2.Delete(sw); err != nil {
+		t.Fatal(err)
+	}
+	// Requests sent without a resource are described by the URL
+	if err := sw.Patch(context.Background(), "system/vlans/1", map[string]interface{}{"description": "default"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Planned operations are not sent and not audited
+	sw.DryRun = true
+	planned := aoscxgo.Vlan{VlanId: 300, Name: "planned"}
+	if err := planned.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		operation    string
+		method       string
+		resourceType string
+		resourceKey  string
+		table        string
+		status       int
+	}{
+		{"create", http.MethodPost, "Vlan", "100", "system/vlans", http.StatusCreated},
+		{"create", http.MethodPost, "LagInterface", "lag1", "system/interfaces", http.StatusCreated},
+		{"update", http.MethodPatch, "LagInterface", "lag1", "system/interfaces", http.StatusNoContent},
+		{"delete", http.MethodDelete, "Vlan", "100", "system/vlans", http.StatusNoContent},
+		{"delete", http.MethodDelete, "Vlan", "100", "system/vlans", http.StatusNotFound},
+		{"delete", http.MethodPut, "L2Interface", "1/1/1", "system/interfaces", http.StatusOK},
+		{"update", http.MethodPatch, "system/vlans", "1", "system/vlans", http.StatusNoContent},
+	}
+
+	events := readAuditLog(t, path)
+	if len(events) != len(want) {
+		for _, event := range events {
+			t.Logf("%s %s", event.Method, event.URL)
+		}
+		t.Fatalf("got %d audit events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		event := events[i]
+		if event.Operation != w.operation || event.Method != w.method {
+			t.Errorf("event %d = %s (%s), want %s (%s)", i, event.Operation, event.Method, w.operation, w.method)
+		}
+		if event.ResourceType != w.resourceType || event.ResourceKey != w.resourceKey || event.Table != w.table {
+			t.Errorf("event %d resource = %s %q in %s, want %s %q in %s", i, event.ResourceType, event.ResourceKey, event.Table, w.resourceType, w.resourceKey, w.table)
+		}
+		if event.StatusCode != w.status {
+			t.Errorf("event %d status = %d, want %d", i, event.StatusCode, w.status)
+		}
+		if event.Hostname != sw.Hostname || event.Username != srv.Client().Username {
+			t.Errorf("event %d switch = %s@%s", i, event.Username, event.Hostname)
+		}
+		if event.Time.IsZero() || event.Duration <= 0 {
+			t.Errorf("event %d time = %v, duration = %v", i, event.Time, event.Duration)
+		}
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(events[2].RequestBody, &body); err != nil || body["description"] != "uplink" {
+		t.Errorf("update request body = %s", events[2].RequestBody)
+	}
+	if len(events[3].RequestBody) != 0 {
+		t.Errorf("delete request body = %s, want none", events[3].RequestBody)
+	}
+}
+
+func TestAuditFullConfig(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := aoscxgo.NewFileAuditSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sw.AuditSink = sink
+
+	filename := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(filename, []byte("hostname audited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fc := aoscxgo.FullConfig{FileName: filename}
+	if _, err := fc.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.RunningConfig(); got != "hostname audited\n" {
+		t.Fatalf("running config = %q", got)
+	}
+
+	// Only the apply changes the switch
+	events := readAuditLog(t, path)
+	if len(events) != 1 {
+		t.Fatalf("got %d audit events, want 1", len(events))
+	}
+	if events[0].Operation != "apply" || events[0].ResourceType != "FullConfig" || events[0].Table != "configs/running-config" {
+		t.Errorf("event = %s %s in %s", events[0].Operation, events[0].ResourceType, events[0].Table)
+	}
+	var config string
+	if err := json.Unmarshal(events[0].RequestBody, &config); err != nil || config != "hostname audited\n" {
+		t.Errorf("request body = %s", events[0].RequestBody)
+	}
+}
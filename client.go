@@ -1,115 +1,322 @@
 package aoscxgo
 
 import (
-	"crypto/tls"
+	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// Client holds the connection to a single switch. Once Connect has returned,
+// a Client is safe for concurrent use by multiple goroutines and should be
+// shared, since AOS-CX limits the number of REST sessions per user.
 type Client struct {
 	// Connection properties.
 	Hostname string `json:"hostname"`
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Version  string `json:"version"`
-	// Generated after Connect
+	// Endpoint options. Scheme defaults to "https" and Port to the scheme
+	// default. BasePath is prepended to "/rest" when the switch is reached
+	// through a reverse proxy, e.g. "/switches/core1".
+	Scheme   string `json:"scheme"`
+	Port     int    `json:"port"`
+	BasePath string `json:"base_path"`
+	// MinVersion and MaxVersion restrict the REST API versions Connect may
+	// pick, e.g. "v10.09" and "v10.11". Connect uses the newest version
+	// offered by the switch within these bounds.
+	MinVersion string `json:"min_version"`
+	MaxVersion string `json:"max_version"`
+	// Credentials, when set, is consulted for the username and password at
+	// every login instead of the Username and Password fields.
+	Credentials CredentialProvider `json:"-"`
+	// Generated after Connect, and refreshed when the session expires.
+	// Use Session to read them while requests may be in flight.
 	Cookie *http.Cookie `json:"cookie"`
 	Csrf   string       `json:"Csrf"`
-	// HTTP transport options.  Note that the VerifyCertificate setting is
-	// only used if you do not specify a HTTP transport yourself.
-	VerifyCertificate bool            `json:"verify_certificate"`
-	Transport         *http.Transport `json:"-"`
+	// HTTP transport options.  Note that the VerifyCertificate setting and
+	// the TLS options below are only used if you do not specify a HTTP
+	// transport yourself. Any http.RoundTripper can be used, such as the
+	// recording and replaying transports of package aoscxtest.
+	VerifyCertificate bool              `json:"verify_certificate"`
+	Transport         http.RoundTripper `json:"-"`
+	// CAFile and RootCAs hold the CAs trusted to sign the switch certificate.
+	// Setting either one enables certificate verification.
+	CAFile  string         `json:"ca_file"`
+	RootCAs *x509.CertPool `json:"-"`
+	// ClientCertFile and ClientKeyFile hold a PEM client certificate and key
+	// presented to the switch.
+	ClientCertFile string `json:"client_cert_file"`
+	ClientKeyFile  string `json:"client_key_file"`
+	// PinnedFingerprints restricts the accepted switch certificates to the
+	// given SHA-256 fingerprints, in hex with or without colons.
+	PinnedFingerprints []string `json:"pinned_fingerprints"`
+	// ServerName overrides the name verified against the switch certificate,
+	// for example when connecting by IP address.
+	ServerName string `json:"server_name"`
+	// MaxConcurrentRequests caps the number of requests in flight to the
+	// switch at any time. Zero means no limit. It must be set before Connect,
+	// which sizes the limit; later changes have no effect.
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+	// RetryPolicy controls retries of transient failures. Nil disables retries.
+	RetryPolicy *RetryPolicy `json:"-"`
+	// Logger receives the client's log output. Every record carries the
+	// switch hostname. When nil, nothing is logged.
+	Logger *slog.Logger `json:"-"`
+	// Middleware wraps every REST call made by resource operations, in order,
+	// with the first entry outermost. Login and logout are not wrapped.
+	Middleware []Middleware `json:"-"`
+	// DryRun, when set, keeps POST, PUT, PATCH and DELETE requests from being
+	// sent. They are captured into Plan instead and answered as if they had
+	// succeeded, while GET requests still go to the switch.
+	DryRun bool `json:"dry_run"`
+	// AuditSink, when set, receives an AuditEvent for every configuration
+	// change sent to the switch.
+	AuditSink AuditSink `json:"-"`
+
+	// sessionMu guards Cookie, Csrf and sessionUser, loginMu serializes
+	// Connect, re-authentication and Logout.
+	sessionMu   sync.RWMutex
+	loginMu     sync.Mutex
+	sessionUser string
+
+	// plan holds the operations captured while DryRun is set.
+	planMu sync.Mutex
+	plan   []PlannedOperation
+
+	// inflight is a semaphore sized by MaxConcurrentRequests.
+	inflightOnce sync.Once
+	inflight     chan struct{}
 }
 
-// fetchLatestAPIVersion fetches the latest API version from the switch
-func fetchLatestAPIVersion(transport *http.Transport, hostname string) (string, error) {
-	url := fmt.Sprintf("https://%s/rest", hostname)
+// baseURL returns the URL of the REST root of the switch, e.g. "https://10.0.0.1/rest".
+func (c *Client) baseURL() string {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	host := c.Hostname
+	if c.Port != 0 {
+		host = net.JoinHostPort(strings.Trim(c.Hostname, "[]"), strconv.Itoa(c.Port))
+	}
+
+	base_path := strings.Trim(c.BasePath, "/")
+	if base_path != "" {
+		base_path = "/" + base_path
+	}
+
+	return scheme + "://" + host + base_path + "/rest"
+}
+
+// restURL returns the URL of the versioned REST API, e.g. "https://10.0.0.1/rest/v10.09".
+// Resource URLs are built by appending the table path to it.
+func (c *Client) restURL() string {
+	return c.baseURL() + "/" + c.Version
+}
+
+// logger returns the configured Logger, or a logger that discards everything.
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger.With("hostname", c.Hostname)
+}
+
+// discardLogger is used when the Client has no Logger.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// sizeInflight creates the request semaphore from MaxConcurrentRequests. Only
+// the first call, normally made by Connect, has an effect.
+func (c *Client) sizeInflight() {
+	c.inflightOnce.Do(func() {
+		if c.MaxConcurrentRequests > 0 {
+			c.inflight = make(chan struct{}, c.MaxConcurrentRequests)
+		}
+	})
+}
+
+// acquire blocks until a request slot is available or ctx is done.
+func (c *Client) acquire(ctx context.Context) error {
+	c.sizeInflight()
+	if c.inflight == nil {
+		return nil
+	}
+
+	select {
+	case c.inflight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a request slot taken by acquire.
+func (c *Client) release() {
+	if c.inflight != nil {
+		<-c.inflight
+	}
+}
+
+// Session returns the current session cookie and CSRF token.
+func (c *Client) Session() (*http.Cookie, string) {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
+	return c.Cookie, c.Csrf
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// sessionUsername returns the username the current session logged in with.
+func (c *Client) sessionUsername() string {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
+	if c.sessionUser != "" {
+		return c.sessionUser
+	}
+	return c.Username
+}
+
+// setSession stores a new session cookie and CSRF token.
+func (c *Client) setSession(cookie *http.Cookie, csrf string) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	c.Cookie = cookie
+	c.Csrf = csrf
+}
+
+// errReauthentication marks a failed login after the session expired. It is
+// never retried, since repeating the login would fail the same way.
+var errReauthentication = errors.New("re-authentication failed")
+
+// reauthenticate logs in again with the stored credentials, unless another
+// goroutine already replaced the session identified by stale_csrf.
+func (c *Client) reauthenticate(ctx context.Context, stale_csrf string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if _, csrf := c.Session(); csrf != stale_csrf {
+		return nil
+	}
+
+	cookie, csrf, err := login(ctx, c)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errReauthentication, err)
+	}
+	c.setSession(cookie, csrf)
+	c.logger().Info("session expired, logged in again")
+	return nil
+}
+
+// fetchAPIVersions fetches the API versions offered by the switch, including the latest one
+func fetchAPIVersions(ctx context.Context, c *Client) ([]string, error) {
+	url := c.baseURL()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create API version request: %w", err)
+		return nil, fmt.Errorf("failed to create API version request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
 	req.Close = false
 
-	res, err := transport.RoundTrip(req)
+	res, err := c.Transport.RoundTrip(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch API versions: %w", err)
+		return nil, fmt.Errorf("failed to fetch API versions: %w", err)
 	}
 	if res == nil {
-		return "", fmt.Errorf("received nil response when fetching API versions")
+		return nil, fmt.Errorf("received nil response when fetching API versions")
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch API versions, status: %s", res.Status)
+		return nil, fmt.Errorf("failed to fetch API versions, status: %s", res.Status)
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read API version response: %w", err)
+		return nil, fmt.Errorf("failed to read API version response: %w", err)
 	}
 
-	// Parse JSON response and extract latest version
+	// Parse JSON response, which maps each version (and "latest") to its details
 	var apiResponse map[string]interface{}
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse API version response: %w", err)
-	}
-
-	// Get the latest version
-	latest, exists := apiResponse["latest"]
-	if !exists {
-		return "", fmt.Errorf("latest version not found in API response")
-	}
-
-	latestMap, ok := latest.(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("latest version has invalid format")
+		return nil, fmt.Errorf("failed to parse API version response: %w", err)
 	}
 
-	version, exists := latestMap["version"]
-	if !exists {
-		return "", fmt.Errorf("version not found in latest API response")
+	var versions []string
+	for key, value := range apiResponse {
+		details, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		version, ok := details["version"].(string)
+		if !ok || version == "" {
+			version = key
+		}
+		if _, err := ParseAPIVersion(version); err == nil {
+			versions = append(versions, version)
+		}
 	}
 
-	versionStr, ok := version.(string)
-	if !ok || versionStr == "" {
-		return "", fmt.Errorf("version is not a valid string")
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions found in API response")
 	}
 
-	log.Printf("Detected latest API version: %s", versionStr)
-	return versionStr, nil
+	c.logger().Debug("detected API versions", "versions", versions)
+	return versions, nil
 }
 
 // Connect creates connection to given Client object.
 func Connect(c *Client) (*Client, error) {
+	return ConnectContext(context.Background(), c)
+}
+
+// ConnectContext is like Connect but uses ctx for the version discovery and login requests.
+// Concurrent calls on the same Client are serialized, and a Client that already
+// holds a session is returned as is instead of opening another session.
+func ConnectContext(ctx context.Context, c *Client) (*Client, error) {
 	var err error
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !c.VerifyCertificate},
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if cookie, _ := c.Session(); cookie != nil {
+		return c, nil
 	}
 
+	c.sizeInflight()
 	if c.Transport == nil {
-		c.Transport = tr
+		tls_config, err := c.buildTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		c.Transport = &http.Transport{
+			TLSClientConfig: tls_config,
+		}
 	}
 
-	// Fetch the latest API version from the switch
-	latestVersion, err := fetchLatestAPIVersion(c.Transport, c.Hostname)
+	// Fetch the API versions offered by the switch and pick the newest allowed one
+	versions, err := fetchAPIVersions(ctx, c)
 	if err != nil {
-		log.Printf("Warning: Could not fetch latest API version, using fallback: %v", err)
+		c.logger().Warn("could not fetch API versions, using fallback", "error", err)
 		// Fall back to user-specified version or default
 		if c.Version == "" {
 			c.Version = "v10.09" // Default fallback
 		}
 	} else {
-		// Use the latest version from the switch
-		c.Version = latestVersion
-		log.Printf("Using API version: %s", c.Version)
+		c.Version, err = c.negotiateVersion(versions)
+		if err != nil {
+			return nil, err
+		}
+		c.logger().Info("using API version", "version", c.Version)
 	}
 
 	// Validate version format (should start with 'v')
@@ -117,53 +324,79 @@ func Connect(c *Client) (*Client, error) {
 		c.Version = "v" + c.Version
 	}
 
-	cookie, csrf, err := login(c.Transport, c.Hostname, c.Version, c.Username, c.Password)
+	if err := c.checkVersionBounds(); err != nil {
+		return nil, err
+	}
+
+	cookie, csrf, err := login(ctx, c)
 
 	if err != nil {
 		return nil, err
 	}
-	c.Cookie = cookie
-	c.Csrf = csrf
+	c.setSession(cookie, csrf)
 	return c, err
 }
 
 // Logout calls the logout endpoint to clear the session.
 func (c *Client) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout but uses ctx for the logout request.
+func (c *Client) LogoutContext(ctx context.Context) error {
 	if c == nil {
 		return errors.New("nil value to Logout")
 	}
-	url := fmt.Sprintf("https://%s/rest/%s/logout", c.Hostname, c.Version)
-	resp := logout(c.Transport, c.Cookie, c.Csrf, url)
+
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	url := c.restURL() + "/logout"
+	cookie, csrf := c.Session()
+	resp, err := logout(ctx, c, cookie, csrf, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.New(resp.Status)
 	}
+	c.setSession(nil, "")
 	return nil
 }
 
-// login performs POST to create a cookie for authentication to the given IP with the provided credentials.
-func login(http_transport *http.Transport, ip string, rest_version string, username string, password string) (*http.Cookie, string, error) {
-	url := fmt.Sprintf("https://%s/rest/%s/login?username=%s&password=%s", ip, rest_version, username, password)
-	req, err := http.NewRequest("POST", url, nil)
+// login performs POST to create a cookie for authentication to the switch with the client credentials.
+// The credentials are sent as a form-encoded body. Firmware that rejects the body
+// is retried once with the legacy query string form.
+func login(ctx context.Context, c *Client) (*http.Cookie, string, error) {
+	username, password, err := c.credentials(ctx)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
+		return nil, "", err
 	}
-	req.Header.Set("accept", "*/*")
-	req.Header.Set("x-use-csrf-token", "true")
-	req.Close = false
 
-	res, err := http_transport.RoundTrip(req)
+	res, err := postLogin(ctx, c, username, password, false)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to connect to switch: %w", err)
+		return nil, "", err
 	}
-	if res == nil {
-		return nil, "", fmt.Errorf("received nil response from switch")
+	if res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnsupportedMediaType {
+		res.Body.Close()
+		c.logger().Debug("login body rejected, retrying with query string credentials", "status", res.StatusCode)
+		res, err = postLogin(ctx, c, username, password, true)
+		if err != nil {
+			return nil, "", err
+		}
 	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("login failed with status: %s", res.Status)
+		api_err := newAPIError("Login Error", res)
+		// Never report the credentials carried in the query string
+		api_err.URL = strings.SplitN(api_err.URL, "?", 2)[0]
+		if sessionLimitReached(api_err) {
+			return nil, "", &SessionLimitError{APIError: api_err}
+		}
+		return nil, "", api_err
 	}
 
-	log.Printf("Login Successful")
-
 	// Check if CSRF token exists
 	csrfTokens := res.Header["X-Csrf-Token"]
 	if len(csrfTokens) == 0 {
@@ -178,36 +411,75 @@ func login(http_transport *http.Transport, ip string, rest_version string, usern
 	}
 	cookie := cookies[0]
 
+	c.logger().Info("login successful", "username", username)
+	c.sessionMu.Lock()
+	c.sessionUser = username
+	c.sessionMu.Unlock()
+
 	return cookie, csrf, nil
 }
 
+// postLogin sends a single login request. When legacy_query is set the credentials
+// are escaped into the query string instead of the request body.
+func postLogin(ctx context.Context, c *Client, username string, password string, legacy_query bool) (*http.Response, error) {
+	url := c.restURL() + "/login"
+	credentials := neturl.Values{
+		"username": {username},
+		"password": {password},
+	}
+
+	var body io.Reader
+	if legacy_query {
+		url += "?" + credentials.Encode()
+	} else {
+		body = strings.NewReader(credentials.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("accept", "*/*")
+	req.Header.Set("x-use-csrf-token", "true")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Close = false
+
+	res, err := c.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to switch: %w", err)
+	}
+	if res == nil {
+		return nil, fmt.Errorf("received nil response from switch")
+	}
+	return res, nil
+}
+
 // logout performs POST to logout using a cookie from the given URL.
-func logout(http_transport *http.Transport, cookie *http.Cookie, csrf string, url string) *http.Response {
-	req, err := http.NewRequest("POST", url, nil)
+func logout(ctx context.Context, c *Client, cookie *http.Cookie, csrf string, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
-		log.Printf("Failed to create logout request: %v", err)
-		return nil
+		return nil, fmt.Errorf("failed to create logout request: %w", err)
 	}
 	req.Header.Set("accept", "*/*")
 	req.Header.Set("x-csrf-token", csrf)
 	req.Close = false
 
 	req.AddCookie(cookie)
-	res, err := http_transport.RoundTrip(req)
+	res, err := c.Transport.RoundTrip(req)
 	if err != nil {
-		log.Printf("Failed to logout from switch: %v", err)
-		return nil
+		return nil, fmt.Errorf("failed to logout from switch: %w", err)
 	}
 	if res == nil {
-		log.Printf("Received nil response during logout")
-		return nil
+		return nil, fmt.Errorf("received nil response during logout")
 	}
 	if res.StatusCode != http.StatusOK {
-		log.Printf("Logout failed with status: %s", res.Status)
-		return res
+		c.logger().Warn("logout failed", "status", res.StatusCode)
+		return res, nil
 	}
 
-	log.Printf("Logout Successful")
+	c.logger().Info("logout successful")
 
-	return res
+	return res, nil
 }
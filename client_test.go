@@ -0,0 +1,451 @@
+package aoscxgo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// logins counts the login requests received by srv.
+func logins(srv *aoscxtest.Server) int {
+	count := 0
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodPost && strings.HasSuffix(req.Path, "/login") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSessionExpiry(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	createVlans(t, sw, 100)
+
+	srv.ExpireSessions()
+	srv.ResetRequests()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for index := 0; index < 16; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			if index%2 == 0 {
+				vlan := aoscxgo.Vlan{VlanId: 100}
+				errs <- vlan.Get(sw)
+			} else {
+				vlan := aoscxgo.Vlan{VlanId: 200 + index, Name: "worker"}
+				errs <- vlan.Create(sw)
+			}
+		}(index)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("request after session expiry error = %v", err)
+		}
+	}
+	if got := srv.Sessions(); got != 1 {
+		t.Errorf("Sessions() = %d, want 1", got)
+	}
+	if got := logins(srv); got != 1 {
+		t.Errorf("logins = %d, want 1", got)
+	}
+	for index := 1; index < 16; index += 2 {
+		if _, ok := srv.Vlan(200 + index); !ok {
+			t.Errorf("VLAN %d was not created by the replayed POST", 200+index)
+		}
+	}
+}
+
+func TestSessionExpiryLoginFails(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.ExpireSessions()
+	srv.SetCredentials("admin", "changed")
+
+	vlan := aoscxgo.Vlan{VlanId: 1}
+	err := vlan.Get(sw)
+	if !errors.Is(err, aoscxgo.ErrUnauthorized) {
+		t.Errorf("Get() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestConnectConcurrent(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	client := srv.Client()
+
+	var wg sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := aoscxgo.Connect(client); err != nil {
+				t.Errorf("Connect() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := srv.Sessions(); got != 1 {
+		t.Errorf("Sessions() = %d, want 1", got)
+	}
+}
+
+func TestSessionLimit(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetMaxSessions(1)
+
+	if _, err := aoscxgo.Connect(srv.Client()); err != nil {
+		t.Fatalf("first Connect() error = %v", err)
+	}
+
+	_, err := aoscxgo.Connect(srv.Client())
+	var limit_err *aoscxgo.SessionLimitError
+	if !errors.Is(err, aoscxgo.ErrSessionLimit) || !errors.As(err, &limit_err) {
+		t.Fatalf("second Connect() error = %v, want ErrSessionLimit", err)
+	}
+	if limit_err.StatusCode == 0 {
+		t.Errorf("SessionLimitError = %+v, want the status of the login", limit_err)
+	}
+	if got := srv.Sessions(); got != 1 {
+		t.Errorf("Sessions() = %d, want 1", got)
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	var inflight, peak int32
+	client := srv.Client()
+	client.MaxConcurrentRequests = 2
+	client.Middleware = append(client.Middleware, func(next http.RoundTripper) http.RoundTripper {
+		return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			current := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				seen := atomic.LoadInt32(&peak)
+				if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return next.RoundTrip(req)
+		})
+	})
+	sw, err := aoscxgo.Connect(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The limit is fixed by Connect
+	sw.MaxConcurrentRequests = 6
+
+	var wg sync.WaitGroup
+	for index := 0; index < 12; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vlan := aoscxgo.Vlan{VlanId: 1}
+			if err := vlan.Get(sw); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("peak in-flight requests = %d, want at most 2", peak)
+	}
+	if peak < 2 {
+		t.Errorf("peak in-flight requests = %d, want the limit to be used", peak)
+	}
+}
+
+// legacyLogin answers login requests that carry a body with 415, as older
+// firmware that only reads credentials from the query string does.
+func legacyLogin(next http.RoundTripper) http.RoundTripper {
+	return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/login") && req.Body != nil && req.Body != http.NoBody {
+			return &http.Response{
+				Status:     "415 Unsupported Media Type",
+				StatusCode: http.StatusUnsupportedMediaType,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// loginRequests returns the login requests received by srv.
+func loginRequests(srv *aoscxtest.Server) []aoscxtest.Request {
+	var requests []aoscxtest.Request
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodPost && strings.HasSuffix(req.Path, "/login") {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+func TestLoginFormBody(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetCredentials("operator", "p&ss=w rd?")
+
+	client := srv.Client()
+	if _, err := aoscxgo.Connect(client); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	requests := loginRequests(srv)
+	if len(requests) != 1 {
+		t.Fatalf("login requests = %d, want 1", len(requests))
+	}
+	form, err := url.ParseQuery(requests[0].Body)
+	if err != nil {
+		t.Fatalf("login body %q is not form encoded: %v", requests[0].Body, err)
+	}
+	if form.Get("username") != "operator" || form.Get("password") != "p&ss=w rd?" {
+		t.Errorf("login body = %v, want the credentials", form)
+	}
+	if requests[0].Query != "" {
+		t.Errorf("login query = %q, want no credentials in the URL", requests[0].Query)
+	}
+}
+
+func TestLoginQueryFallback(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetCredentials("operator", "p&ss=w rd?")
+
+	client := srv.Client()
+	client.Transport = legacyLogin(client.Transport)
+	if _, err := aoscxgo.Connect(client); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	requests := loginRequests(srv)
+	if len(requests) != 1 {
+		t.Fatalf("login requests = %d, want 1", len(requests))
+	}
+	query, err := url.ParseQuery(requests[0].Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("username") != "operator" || query.Get("password") != "p&ss=w rd?" {
+		t.Errorf("login query = %v, want the escaped credentials", query)
+	}
+}
+
+func TestLoginQueryFallbackRedactsError(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client := srv.Client()
+	client.Password = "wrong-secret"
+	client.Transport = legacyLogin(client.Transport)
+	_, err := aoscxgo.Connect(client)
+	if !errors.Is(err, aoscxgo.ErrUnauthorized) {
+		t.Fatalf("Connect() error = %v, want ErrUnauthorized", err)
+	}
+	if strings.Contains(err.Error(), "wrong-secret") {
+		t.Errorf("Connect() error = %q, want the password redacted", err)
+	}
+	var api_err *aoscxgo.APIError
+	if !errors.As(err, &api_err) {
+		t.Fatalf("Connect() error = %v, want an *APIError", err)
+	}
+	if strings.Contains(api_err.URL, "?") {
+		t.Errorf("APIError URL = %q, want no query string", api_err.URL)
+	}
+}
+
+func TestLoginWithoutSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		strip   func(res *http.Response)
+		wantErr string
+	}{
+		{"no CSRF token", func(res *http.Response) { res.Header.Del("X-Csrf-Token") }, "no CSRF token"},
+		{"no cookie", func(res *http.Response) { res.Header.Del("Set-Cookie") }, "no cookies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := aoscxtest.NewServer()
+			t.Cleanup(srv.Close)
+
+			var buf bytes.Buffer
+			client := srv.Client()
+			client.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+			transport := client.Transport
+			client.Transport = aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				res, err := transport.RoundTrip(req)
+				if err == nil && strings.HasSuffix(req.URL.Path, "/login") {
+					tt.strip(res)
+				}
+				return res, err
+			})
+
+			_, err := aoscxgo.Connect(client)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Connect() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Contains(buf.String(), "login successful") {
+				t.Errorf("failed login was logged as successful:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+// closeRecorder records whether a response body was closed.
+type closeRecorder struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed.Store(true)
+	return nil
+}
+
+func TestLogoutClosesBody(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			body := &closeRecorder{Reader: strings.NewReader("")}
+			sw.Transport = aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{Status: http.StatusText(status), StatusCode: status, Header: http.Header{}, Body: body, Request: req}, nil
+			})
+
+			err := sw.Logout()
+			if (err == nil) != (status == http.StatusOK) {
+				t.Errorf("Logout() error = %v with status %d", err, status)
+			}
+			if !body.closed.Load() {
+				t.Error("logout response body was not closed")
+			}
+		})
+	}
+}
+
+// logRecords decodes the JSON log records written to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("log output is not JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		legacy bool
+	}{
+		{name: "form body login"},
+		{name: "query string login", legacy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := aoscxtest.NewServer()
+			t.Cleanup(srv.Close)
+			srv.SetCredentials("operator", "p4ss-secret")
+
+			var buf bytes.Buffer
+			client := srv.Client()
+			client.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			if tt.legacy {
+				client.Transport = legacyLogin(client.Transport)
+			}
+			sw, err := aoscxgo.Connect(client)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			vlan := aoscxgo.Vlan{VlanId: 1}
+			if err := vlan.Get(sw); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			if strings.Contains(buf.String(), "p4ss-secret") {
+				t.Errorf("log output contains the password:\n%s", buf.String())
+			}
+			var completed, logged_in map[string]interface{}
+			for _, record := range logRecords(t, &buf) {
+				if record["hostname"] != client.Hostname {
+					t.Errorf("record %v has hostname %v, want %s", record["msg"], record["hostname"], client.Hostname)
+				}
+				if record["msg"] == "request completed" && strings.HasSuffix(record["path"].(string), "/system/vlans/1") {
+					completed = record
+				}
+				if record["msg"] == "login successful" {
+					logged_in = record
+				}
+			}
+			if logged_in == nil || logged_in["username"] != "operator" {
+				t.Errorf("login successful = %v, want the username", logged_in)
+			}
+			if completed == nil {
+				t.Fatal("no request completed record for the VLAN")
+			}
+			if completed["method"] != http.MethodGet || completed["status"] != float64(http.StatusOK) {
+				t.Errorf("request completed = %v, want GET with status 200", completed)
+			}
+			if _, ok := completed["latency"].(float64); !ok {
+				t.Errorf("request completed latency = %v, want a duration", completed["latency"])
+			}
+		})
+	}
+}
+
+func TestLoggerNil(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	sw, err := aoscxgo.Connect(srv.Client())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	vlan := aoscxgo.Vlan{VlanId: 1}
+	if err := vlan.Get(sw); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := sw.Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		// Ignore the log output of the test servers
+		if line != "" && !strings.Contains(line, `msg="http: `) {
+			t.Errorf("client without a Logger wrote: %s", line)
+		}
+	}
+}
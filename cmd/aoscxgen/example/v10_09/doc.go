@@ -0,0 +1,6 @@
+// Package v10_09 holds the models generated by aoscxgen from the REST API
+// v10.09 schema in cmd/aoscxgen/testdata. It shows the output of the generator
+// and is regenerated with go generate.
+package v10_09
+
+//go:generate go run github.com/felixn-unity/aoscxgo/cmd/aoscxgen -schema ../../testdata/schema.json -o zz_generated.go
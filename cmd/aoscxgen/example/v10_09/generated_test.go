@@ -0,0 +1,69 @@
+package v10_09_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+	"github.com/felixn-unity/aoscxgo/cmd/aoscxgen/example/v10_09"
+)
+
+func TestGeneratedModels(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	defer srv.Close()
+	sw, err := aoscxgo.Connect(srv.Client())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	vlan := v10_09.Vlan{ID: 100, Name: "uplink", Admin: "up"}
+	if err := vlan.Create(sw); err != nil {
+		t.Fatalf("Vlan.Create: %v", err)
+	}
+	vlan.Description = "core uplink"
+	if err := vlan.Update(sw); err != nil {
+		t.Fatalf("Vlan.Update: %v", err)
+	}
+	got := v10_09.Vlan{ID: 100}
+	if err := got.Get(sw); err != nil {
+		t.Fatalf("Vlan.Get: %v", err)
+	}
+	if !got.GetStatus() || got.Name != "uplink" || got.Description != "core uplink" || got.Admin != "up" {
+		t.Errorf("Vlan.Get() = %+v", got)
+	}
+
+	iface := v10_09.Interface{Name: "vlan100", Type: "vlan", Routing: true, Ip4Address: "10.0.0.1/24"}
+	if err := iface.Create(sw); err != nil {
+		t.Fatalf("Interface.Create: %v", err)
+	}
+	address := v10_09.Ip6Address{InterfaceName: "vlan100", Address: "2001:db8::1/64", RaPrefix: true}
+	if err := address.Create(sw); err != nil {
+		t.Fatalf("Ip6Address.Create: %v", err)
+	}
+	got_address := v10_09.Ip6Address{InterfaceName: "vlan100", Address: "2001:db8::1/64"}
+	if err := got_address.Get(sw); err != nil {
+		t.Fatalf("Ip6Address.Get: %v", err)
+	}
+	if !got_address.RaPrefix || got_address.InterfaceName != "vlan100" {
+		t.Errorf("Ip6Address.Get() = %+v", got_address)
+	}
+	if err := address.Delete(sw); err != nil {
+		t.Fatalf("Ip6Address.Delete: %v", err)
+	}
+	if err := got_address.Get(sw); !errors.Is(err, aoscxgo.ErrNotFound) || got_address.GetStatus() {
+		t.Errorf("Ip6Address.Get() after Delete error = %v", err)
+	}
+
+	if err := vlan.Delete(sw); err != nil {
+		t.Fatalf("Vlan.Delete: %v", err)
+	}
+	if vlan.GetStatus() {
+		t.Error("Vlan.GetStatus() = true after Delete")
+	}
+
+	missing := v10_09.Ip6Address{Address: "2001:db8::2/64"}
+	if err := missing.Create(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Create() without InterfaceName error = %v", err)
+	}
+}
@@ -0,0 +1,446 @@
+// Code generated by aoscxgen from schema.json; DO NOT EDIT.
+
+package v10_09
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// APIVersion is the REST API version of the schema the types were generated from.
+// Every request uses it, whatever version the Client negotiated.
+const APIVersion = "v10.09"
+
+// Interface is a row of the system/interfaces table.
+type Interface struct {
+	// Administrative state of the interface.
+	Admin       string `json:"admin,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Primary IPv4 address with prefix length.
+	Ip4Address          string   `json:"ip4_address,omitempty"`
+	Ip4AddressSecondary []string `json:"ip4_address_secondary,omitempty"`
+	// Link state of the interface.
+	LinkState string `json:"link_state,omitempty"`
+	MTU       int64  `json:"mtu,omitempty"`
+	// Interface name, e.g. 1/1/1 or lag10.
+	Name        string                 `json:"name,omitempty"`
+	OtherConfig map[string]interface{} `json:"other_config,omitempty"`
+	// Whether the interface is routed (layer 3).
+	Routing    bool             `json:"routing,omitempty"`
+	Statistics map[string]int64 `json:"statistics,omitempty"`
+	// Interface type: system, lag, vlan, loopback or tunnel.
+	Type string `json:"type,omitempty"`
+	// VLAN mode: access, native-tagged or native-untagged.
+	VlanMode string `json:"vlan_mode,omitempty"`
+	// Access or native VLAN of the interface.
+	VlanTag map[string]string `json:"vlan_tag,omitempty"`
+	// VLANs allowed on a trunk.
+	VlanTrunks   map[string]string `json:"vlan_trunks,omitempty"`
+	materialized bool
+}
+
+// CollectionURI returns the URI of the system/interfaces table.
+func (r *Interface) CollectionURI() string {
+	return "/rest/" + APIVersion + "/system/interfaces"
+}
+
+// GetURI returns URI of the Interface.
+func (r *Interface) GetURI() string {
+	return r.CollectionURI() + "/" + url.PathEscape(r.Name)
+}
+
+// checkKeys returns an error if a key of the Interface is missing.
+func (r *Interface) checkKeys(op string) error {
+	if r.Name == "" {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value Name",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+	return nil
+}
+
+// body returns the attributes sent to the switch. Attributes with a zero value
+// are left out, keys are only sent on create.
+func (r *Interface) body(create bool) map[string]interface{} {
+	body := map[string]interface{}{}
+	if r.Admin != "" {
+		body["admin"] = r.Admin
+	}
+	if r.Description != "" {
+		body["description"] = r.Description
+	}
+	if r.Ip4Address != "" {
+		body["ip4_address"] = r.Ip4Address
+	}
+	if len(r.Ip4AddressSecondary) != 0 {
+		body["ip4_address_secondary"] = r.Ip4AddressSecondary
+	}
+	if r.MTU != 0 {
+		body["mtu"] = r.MTU
+	}
+	if create {
+		body["name"] = r.Name
+	}
+	if len(r.OtherConfig) != 0 {
+		body["other_config"] = r.OtherConfig
+	}
+	if r.Routing {
+		body["routing"] = r.Routing
+	}
+	if r.Type != "" {
+		body["type"] = r.Type
+	}
+	if r.VlanMode != "" {
+		body["vlan_mode"] = r.VlanMode
+	}
+	if len(r.VlanTag) != 0 {
+		body["vlan_tag"] = r.VlanTag
+	}
+	if len(r.VlanTrunks) != 0 {
+		body["vlan_trunks"] = r.VlanTrunks
+	}
+	return body
+}
+
+// Create performs POST to create the Interface on the given Client object.
+func (r *Interface) Create(c *aoscxgo.Client) error {
+	return r.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (r *Interface) CreateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Create Error"); err != nil {
+		return err
+	}
+	if err := c.Post(ctx, r.CollectionURI(), r.body(true)); err != nil {
+		return err
+	}
+	r.materialized = true
+	return nil
+}
+
+// Get performs GET to retrieve the Interface from the given Client object.
+func (r *Interface) Get(c *aoscxgo.Client) error {
+	return r.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (r *Interface) GetContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Retrieval Error"); err != nil {
+		return err
+	}
+	row := Interface{}
+	if err := c.Get(ctx, r.GetURI(), nil, &row); err != nil {
+		r.materialized = false
+		return err
+	}
+	row.Name = r.Name
+	row.materialized = true
+	*r = row
+	return nil
+}
+
+// Update performs PATCH to update the Interface on the given Client object.
+func (r *Interface) Update(c *aoscxgo.Client) error {
+	return r.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (r *Interface) UpdateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Update Error"); err != nil {
+		return err
+	}
+	return c.Patch(ctx, r.GetURI(), r.body(false))
+}
+
+// Delete performs DELETE to remove the Interface from the given Client object.
+func (r *Interface) Delete(c *aoscxgo.Client) error {
+	return r.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (r *Interface) DeleteContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Delete Error"); err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, r.GetURI()); err != nil {
+		return err
+	}
+	r.materialized = false
+	return nil
+}
+
+// GetStatus returns True if the Interface exists on Client object or False if not.
+func (r *Interface) GetStatus() bool {
+	return r.materialized
+}
+
+// Ip6Address is a row of the system/interfaces/{name}/ip6_addresses table.
+type Ip6Address struct {
+	// InterfaceName is the key of the interface the row belongs to.
+	InterfaceName string `json:"-"`
+
+	// IPv6 address with prefix length.
+	Address           string `json:"address,omitempty"`
+	NodeAddress       bool   `json:"node_address,omitempty"`
+	Origin            string `json:"origin,omitempty"`
+	PreferredLifetime int    `json:"preferred_lifetime,omitempty"`
+	// Whether the prefix is advertised in router advertisements.
+	RaPrefix      bool `json:"ra_prefix,omitempty"`
+	ValidLifetime int  `json:"valid_lifetime,omitempty"`
+	materialized  bool
+}
+
+// CollectionURI returns the URI of the system/interfaces/{name}/ip6_addresses table.
+func (r *Ip6Address) CollectionURI() string {
+	return "/rest/" + APIVersion + "/system/interfaces/" + url.PathEscape(r.InterfaceName) + "/ip6_addresses"
+}
+
+// GetURI returns URI of the Ip6Address.
+func (r *Ip6Address) GetURI() string {
+	return r.CollectionURI() + "/" + url.PathEscape(r.Address)
+}
+
+// checkKeys returns an error if a key of the Ip6Address is missing.
+func (r *Ip6Address) checkKeys(op string) error {
+	if r.InterfaceName == "" {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value InterfaceName",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+	if r.Address == "" {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value Address",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+	return nil
+}
+
+// body returns the attributes sent to the switch. Attributes with a zero value
+// are left out, keys are only sent on create.
+func (r *Ip6Address) body(create bool) map[string]interface{} {
+	body := map[string]interface{}{}
+	if create {
+		body["address"] = r.Address
+	}
+	if r.NodeAddress {
+		body["node_address"] = r.NodeAddress
+	}
+	if r.PreferredLifetime != 0 {
+		body["preferred_lifetime"] = r.PreferredLifetime
+	}
+	if r.RaPrefix {
+		body["ra_prefix"] = r.RaPrefix
+	}
+	if r.ValidLifetime != 0 {
+		body["valid_lifetime"] = r.ValidLifetime
+	}
+	return body
+}
+
+// Create performs POST to create the Ip6Address on the given Client object.
+func (r *Ip6Address) Create(c *aoscxgo.Client) error {
+	return r.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (r *Ip6Address) CreateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Create Error"); err != nil {
+		return err
+	}
+	if err := c.Post(ctx, r.CollectionURI(), r.body(true)); err != nil {
+		return err
+	}
+	r.materialized = true
+	return nil
+}
+
+// Get performs GET to retrieve the Ip6Address from the given Client object.
+func (r *Ip6Address) Get(c *aoscxgo.Client) error {
+	return r.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (r *Ip6Address) GetContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Retrieval Error"); err != nil {
+		return err
+	}
+	row := Ip6Address{
+		InterfaceName: r.InterfaceName,
+	}
+	if err := c.Get(ctx, r.GetURI(), nil, &row); err != nil {
+		r.materialized = false
+		return err
+	}
+	row.Address = r.Address
+	row.materialized = true
+	*r = row
+	return nil
+}
+
+// Delete performs DELETE to remove the Ip6Address from the given Client object.
+func (r *Ip6Address) Delete(c *aoscxgo.Client) error {
+	return r.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (r *Ip6Address) DeleteContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Delete Error"); err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, r.GetURI()); err != nil {
+		return err
+	}
+	r.materialized = false
+	return nil
+}
+
+// GetStatus returns True if the Ip6Address exists on Client object or False if not.
+func (r *Ip6Address) GetStatus() bool {
+	return r.materialized
+}
+
+// Vlan is a row of the system/vlans table.
+type Vlan struct {
+	// Administrative state of the VLAN, up or down.
+	Admin string `json:"admin,omitempty"`
+	// Free form description of the VLAN.
+	Description string `json:"description,omitempty"`
+	// VLAN identifier.
+	ID int `json:"id,omitempty"`
+	// VLAN name. Must be unique.
+	Name string `json:"name,omitempty"`
+	// Operational state of the VLAN.
+	OperState string `json:"oper_state,omitempty"`
+	// Type of VLAN: static, dynamic or internal.
+	Type string `json:"type,omitempty"`
+	// Whether the VLAN carries voice traffic.
+	Voice        bool `json:"voice,omitempty"`
+	materialized bool
+}
+
+// CollectionURI returns the URI of the system/vlans table.
+func (r *Vlan) CollectionURI() string {
+	return "/rest/" + APIVersion + "/system/vlans"
+}
+
+// GetURI returns URI of the Vlan.
+func (r *Vlan) GetURI() string {
+	return r.CollectionURI() + "/" + strconv.Itoa(r.ID)
+}
+
+// checkKeys returns an error if a key of the Vlan is missing.
+func (r *Vlan) checkKeys(op string) error {
+	if r.ID == 0 {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value ID",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+	return nil
+}
+
+// body returns the attributes sent to the switch. Attributes with a zero value
+// are left out, keys are only sent on create.
+func (r *Vlan) body(create bool) map[string]interface{} {
+	body := map[string]interface{}{}
+	if r.Admin != "" {
+		body["admin"] = r.Admin
+	}
+	if r.Description != "" {
+		body["description"] = r.Description
+	}
+	if create {
+		body["id"] = r.ID
+	}
+	if r.Name != "" {
+		body["name"] = r.Name
+	}
+	if r.Type != "" {
+		body["type"] = r.Type
+	}
+	if r.Voice {
+		body["voice"] = r.Voice
+	}
+	return body
+}
+
+// Create performs POST to create the Vlan on the given Client object.
+func (r *Vlan) Create(c *aoscxgo.Client) error {
+	return r.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (r *Vlan) CreateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Create Error"); err != nil {
+		return err
+	}
+	if err := c.Post(ctx, r.CollectionURI(), r.body(true)); err != nil {
+		return err
+	}
+	r.materialized = true
+	return nil
+}
+
+// Get performs GET to retrieve the Vlan from the given Client object.
+func (r *Vlan) Get(c *aoscxgo.Client) error {
+	return r.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (r *Vlan) GetContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Retrieval Error"); err != nil {
+		return err
+	}
+	row := Vlan{}
+	if err := c.Get(ctx, r.GetURI(), nil, &row); err != nil {
+		r.materialized = false
+		return err
+	}
+	row.ID = r.ID
+	row.materialized = true
+	*r = row
+	return nil
+}
+
+// Update performs PATCH to update the Vlan on the given Client object.
+func (r *Vlan) Update(c *aoscxgo.Client) error {
+	return r.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (r *Vlan) UpdateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Update Error"); err != nil {
+		return err
+	}
+	return c.Patch(ctx, r.GetURI(), r.body(false))
+}
+
+// Delete performs DELETE to remove the Vlan from the given Client object.
+func (r *Vlan) Delete(c *aoscxgo.Client) error {
+	return r.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (r *Vlan) DeleteContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Delete Error"); err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, r.GetURI()); err != nil {
+		return err
+	}
+	r.materialized = false
+	return nil
+}
+
+// GetStatus returns True if the Vlan exists on Client object or False if not.
+func (r *Vlan) GetStatus() bool {
+	return r.materialized
+}
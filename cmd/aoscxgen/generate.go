@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// initialisms are the words written in upper case in Go identifiers.
+var initialisms = map[string]string{
+	"id":   "ID",
+	"ip":   "IP",
+	"mac":  "MAC",
+	"mtu":  "MTU",
+	"uri":  "URI",
+	"url":  "URL",
+	"uuid": "UUID",
+	"vrf":  "VRF",
+}
+
+// file is the data of a generated file.
+type file struct {
+	Schema  string
+	Package string
+	Version string
+	Imports []string
+	// ModuleImports are imported after the standard library.
+	ModuleImports []string
+	Resources     []*resource
+}
+
+// resource is the data of a generated type for one table.
+type resource struct {
+	Type    string
+	Table   string
+	Parents []*field
+	Key     *field
+	Fields  []*field
+	// CollectionURI is the Go expression of the collection URI.
+	CollectionURI string
+	Create        bool
+	Update        string
+	Delete        bool
+}
+
+// field is a struct field of a generated type.
+type field struct {
+	Name    string
+	GoType  string
+	JSON    string
+	Comment string
+	// ReadOnly attributes are decoded but never sent, Key attributes are only
+	// sent on create.
+	ReadOnly bool
+	Key      bool
+	// Zero and NonZero are the Go conditions that are true when the field is
+	// unset and set.
+	Zero    string
+	NonZero string
+	// Escaped is the Go expression of the field as a URI path segment.
+	Escaped string
+}
+
+// generate renders the Go source for tables of swagger.
+func generate(swagger *Swagger, tables []*Table, schema_path, package_name string) ([]byte, error) {
+	data := file{
+		Schema:  filepath.ToSlash(filepath.Base(schema_path)),
+		Package: package_name,
+		Version: swagger.Version(),
+	}
+
+	imports := map[string]bool{"context": true, "fmt": true, "github.com/felixn-unity/aoscxgo": true}
+	types := map[string]bool{}
+	for _, table := range tables {
+		r, err := newResource(swagger, table, types)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", table.Path, err)
+		}
+		for _, f := range append(append([]*field{r.Key}, r.Parents...), r.Fields...) {
+			switch {
+			case strings.Contains(f.Escaped, "url."):
+				imports["net/url"] = true
+			case strings.Contains(f.Escaped, "strconv."):
+				imports["strconv"] = true
+			}
+			if strings.Contains(f.GoType, "json.") {
+				imports["encoding/json"] = true
+			}
+		}
+		data.Resources = append(data.Resources, r)
+	}
+
+	for path := range imports {
+		if strings.Contains(path, ".") {
+			data.ModuleImports = append(data.ModuleImports, path)
+		} else {
+			data.Imports = append(data.Imports, path)
+		}
+	}
+	sort.Strings(data.Imports)
+	sort.Strings(data.ModuleImports)
+
+	var source bytes.Buffer
+	if err := fileTemplate.Execute(&source, data); err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, source.Bytes())
+	}
+	return formatted, nil
+}
+
+// newResource builds the type of table, naming it after the table unless the
+// name is already taken by another table.
+func newResource(swagger *Swagger, table *Table, types map[string]bool) (*resource, error) {
+	r := &resource{
+		Type:   goName(singular(table.Name)),
+		Table:  table.Path,
+		Create: table.Create,
+		Delete: table.Delete,
+	}
+	if table.Update != "" {
+		r.Update = goName(table.Update)
+	}
+	for index := len(table.Parents) - 1; types[r.Type] && index >= 0; index-- {
+		r.Type = goName(singular(table.Parents[index].Table)) + r.Type
+	}
+	if types[r.Type] {
+		return nil, fmt.Errorf("type %s is generated twice", r.Type)
+	}
+	types[r.Type] = true
+
+	names := make([]string, 0, len(table.Schema.Properties))
+	for name := range table.Schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, err := swagger.resolve(table.Schema.Properties[name])
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		f := &field{
+			Name:     goName(name),
+			GoType:   goType(property),
+			JSON:     name,
+			Comment:  comment(property.Description),
+			ReadOnly: property.ReadOnly,
+			Key:      name == table.Key.Name,
+		}
+		f.Zero, f.NonZero = conditions("r."+f.Name, f.GoType)
+		f.Escaped = escaped("r."+f.Name, f.GoType)
+		if f.Key {
+			r.Key = f
+		}
+		r.Fields = append(r.Fields, f)
+	}
+	if r.Key == nil {
+		r.Key = keyField(goName(table.Key.Name), table.Key, table.Key.Name)
+		r.Fields = append([]*field{r.Key}, r.Fields...)
+	}
+
+	uri := `"/rest/" + APIVersion + "/`
+	for _, segment := range strings.Split(table.Path, "/") {
+		if !isParameter(segment) {
+			uri += segment + "/"
+			continue
+		}
+		parent := table.Parents[len(r.Parents)]
+		f := keyField(goName(singular(parent.Table))+goName(parent.Param.Name), parent.Param, "-")
+		f.Comment = f.Name + " is the key of the " + singular(parent.Table) + " the row belongs to."
+		r.Parents = append(r.Parents, f)
+		uri += `" + ` + f.Escaped + ` + "/`
+	}
+	r.CollectionURI = strings.TrimSuffix(uri, "/") + `"`
+	return r, nil
+}
+
+// keyField returns the field holding a path parameter.
+func keyField(name string, param Parameter, json_name string) *field {
+	f := &field{
+		Name:   name,
+		GoType: goType(&Schema{Type: param.Type, Format: param.Format}),
+		JSON:   json_name,
+		Key:    true,
+	}
+	f.Zero, f.NonZero = conditions("r."+f.Name, f.GoType)
+	f.Escaped = escaped("r."+f.Name, f.GoType)
+	return f
+}
+
+// goType maps a schema to a Go type. Objects without properties become maps,
+// anything that cannot be typed is kept as raw JSON.
+func goType(schema *Schema) string {
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "boolean":
+		return "bool"
+	case "integer":
+		if schema.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "array":
+		if schema.Items == nil {
+			return "[]json.RawMessage"
+		}
+		return "[]" + goType(schema.Items)
+	case "object":
+		var additional Schema
+		if len(schema.Properties) == 0 && json.Unmarshal(schema.AdditionalProperties, &additional) == nil && additional.Type != "" {
+			return "map[string]" + goType(&additional)
+		}
+		if len(schema.Properties) == 0 && len(schema.AdditionalProperties) > 0 {
+			return "map[string]interface{}"
+		}
+	}
+	return "json.RawMessage"
+}
+
+// conditions returns the Go conditions that are true when value is unset and
+// set.
+func conditions(value, go_type string) (string, string) {
+	switch go_type {
+	case "string":
+		return value + ` == ""`, value + ` != ""`
+	case "bool":
+		return "!" + value, value
+	case "int", "int64", "float64":
+		return value + " == 0", value + " != 0"
+	}
+	return "len(" + value + ") == 0", "len(" + value + ") != 0"
+}
+
+// escaped returns the Go expression of value as a URI path segment.
+func escaped(value, go_type string) string {
+	switch go_type {
+	case "int":
+		return "strconv.Itoa(" + value + ")"
+	case "int64":
+		return "strconv.FormatInt(" + value + ", 10)"
+	}
+	return "url.PathEscape(" + value + ")"
+}
+
+// goName converts an attribute name such as ip4_address_secondary to a Go
+// identifier such as Ip4AddressSecondary.
+func goName(name string) string {
+	var result strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			result.WriteString(initialism)
+			continue
+		}
+		result.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if result.Len() == 0 || unicode.IsDigit(rune(result.String()[0])) {
+		return "X" + result.String()
+	}
+	return result.String()
+}
+
+// singular returns the singular of a table name, e.g. ip6_addresses → ip6_address.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// comment returns a schema description on one line.
+func comment(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by aoscxgen from {{.Schema}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+{{range .ModuleImports}}
+	"{{.}}"
+{{- end}}
+)
+
+// APIVersion is the REST API version of the schema the types were generated from.
+// Every request uses it, whatever version the Client negotiated.
+const APIVersion = "{{.Version}}"
+{{range .Resources}}{{$r := .}}
+// {{.Type}} is a row of the {{.Table}} table.
+type {{.Type}} struct {
+{{- range .Parents}}
+	// {{.Comment}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"-"` + "`" + `
+{{- end}}
+{{- if .Parents}}
+{{end}}
+{{- range .Fields}}
+{{- if .Comment}}
+	// {{.Comment}}
+{{- end}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSON}},omitempty"` + "`" + `
+{{- end}}
+	materialized bool
+}
+
+// CollectionURI returns the URI of the {{.Table}} table.
+func (r *{{.Type}}) CollectionURI() string {
+	return {{.CollectionURI}}
+}
+
+// GetURI returns URI of the {{.Type}}.
+func (r *{{.Type}}) GetURI() string {
+	return r.CollectionURI() + "/" + {{.Key.Escaped}}
+}
+
+// checkKeys returns an error if a key of the {{.Type}} is missing.
+func (r *{{.Type}}) checkKeys(op string) error {
+{{- range .Parents}}
+	if {{.Zero}} {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value {{.Name}}",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+{{- end}}
+	if {{.Key.Zero}} {
+		return &aoscxgo.RequestError{
+			StatusCode: "Missing Required Value {{.Key.Name}}",
+			Err:        fmt.Errorf("%s: %w", op, aoscxgo.ErrValidation),
+		}
+	}
+	return nil
+}
+
+// body returns the attributes sent to the switch. Attributes with a zero value
+// are left out, keys are only sent on create.
+func (r *{{.Type}}) body(create bool) map[string]interface{} {
+	body := map[string]interface{}{}
+{{- range .Fields}}
+{{- if .Key}}
+	if create {
+		body["{{.JSON}}"] = r.{{.Name}}
+	}
+{{- else if not .ReadOnly}}
+	if {{.NonZero}} {
+		body["{{.JSON}}"] = r.{{.Name}}
+	}
+{{- end}}
+{{- end}}
+	return body
+}
+{{if .Create}}
+// Create performs POST to create the {{.Type}} on the given Client object.
+func (r *{{.Type}}) Create(c *aoscxgo.Client) error {
+	return r.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (r *{{.Type}}) CreateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Create Error"); err != nil {
+		return err
+	}
+	if err := c.Post(ctx, r.CollectionURI(), r.body(true)); err != nil {
+		return err
+	}
+	r.materialized = true
+	return nil
+}
+{{end}}
+// Get performs GET to retrieve the {{.Type}} from the given Client object.
+func (r *{{.Type}}) Get(c *aoscxgo.Client) error {
+	return r.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (r *{{.Type}}) GetContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Retrieval Error"); err != nil {
+		return err
+	}
+	row := {{.Type}}{
+{{- range .Parents}}
+		{{.Name}}: r.{{.Name}},
+{{- end}}
+	}
+	if err := c.Get(ctx, r.GetURI(), nil, &row); err != nil {
+		r.materialized = false
+		return err
+	}
+	row.{{.Key.Name}} = r.{{.Key.Name}}
+	row.materialized = true
+	*r = row
+	return nil
+}
+{{if .Update}}
+// Update performs {{if eq .Update "Patch"}}PATCH{{else}}PUT{{end}} to update the {{.Type}} on the given Client object.
+func (r *{{.Type}}) Update(c *aoscxgo.Client) error {
+	return r.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (r *{{.Type}}) UpdateContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Update Error"); err != nil {
+		return err
+	}
+	return c.{{.Update}}(ctx, r.GetURI(), r.body(false))
+}
+{{end}}{{if .Delete}}
+// Delete performs DELETE to remove the {{.Type}} from the given Client object.
+func (r *{{.Type}}) Delete(c *aoscxgo.Client) error {
+	return r.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (r *{{.Type}}) DeleteContext(ctx context.Context, c *aoscxgo.Client) error {
+	if err := r.checkKeys("Delete Error"); err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, r.GetURI()); err != nil {
+		return err
+	}
+	r.materialized = false
+	return nil
+}
+{{end}}
+// GetStatus returns True if the {{.Type}} exists on Client object or False if not.
+func (r *{{.Type}}) GetStatus() bool {
+	return r.materialized
+}
+{{end}}`))
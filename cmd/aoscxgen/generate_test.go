@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestGenerateExample checks that the example package is up to date with the
+// generator and the test schema.
+func TestGenerateExample(t *testing.T) {
+	swagger, err := loadSwagger("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := swagger.Tables(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(swagger, tables, "../../testdata/schema.json", "v10_09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("example/v10_09/zz_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("example/v10_09/zz_generated.go is out of date, run go generate ./cmd/aoscxgen/...")
+	}
+}
+
+func TestTables(t *testing.T) {
+	swagger, err := loadSwagger("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version := swagger.Version(); version != "v10.09" {
+		t.Errorf("Version() = %q", version)
+	}
+
+	tables, err := swagger.Tables([]string{"system/vlans", "system/interfaces/{name}/ip6_addresses"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("Tables() returned %d tables", len(tables))
+	}
+	address, vlans := tables[0], tables[1]
+	if address.Key.Name != "address" || len(address.Parents) != 1 || address.Parents[0].Table != "interfaces" || address.Update != "" {
+		t.Errorf("ip6_addresses table = %+v", address)
+	}
+	if vlans.Key.Name != "id" || vlans.Key.Type != "integer" || !vlans.Create || vlans.Update != "patch" || !vlans.Delete {
+		t.Errorf("vlans table = %+v", vlans)
+	}
+}
+
+func TestNames(t *testing.T) {
+	for name, want := range map[string]string{
+		"ip4_address_secondary": "Ip4AddressSecondary",
+		"id":                    "ID",
+		"vrf_mtu":               "VRFMTU",
+		"802.1x":                "X8021x",
+	} {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	for name, want := range map[string]string{
+		"vlans":         "vlan",
+		"ip6_addresses": "ip6_address",
+		"policies":      "policy",
+		"access":        "access",
+	} {
+		if got := singular(name); got != want {
+			t.Errorf("singular(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Command aoscxgen generates typed models for the tables of an AOS-CX REST API
+// schema. For each table it emits a struct with the attributes of a row, the
+// URI of the row and Create, Get, Update, Delete and GetStatus methods that use
+// the low-level REST methods of aoscxgo.Client.
+//
+// The schema is the Swagger document the switch serves for one API version,
+// saved to a local file, e.g.
+//
+//	//go:generate go run github.com/felixn-unity/aoscxgo/cmd/aoscxgen -schema aoscx-10.09.json -o zz_generated.go
+//
+// Types for several API versions are generated into one package per version.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	schema_path := flag.String("schema", "", "path of the Swagger JSON schema")
+	output := flag.String("o", "", "output file, standard output if empty")
+	package_name := flag.String("package", "", "package name, derived from the API version if empty (v10.09 gives v10_09)")
+	tables := flag.String("tables", "", "comma-separated collection paths to generate, e.g. system/vlans; all tables if empty")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("aoscxgen: ")
+	if *schema_path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	swagger, err := loadSwagger(*schema_path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if swagger.Version() == "" {
+		log.Fatalf("%s: no API version in basePath or info.version", *schema_path)
+	}
+
+	var only []string
+	if *tables != "" {
+		only = strings.Split(*tables, ",")
+	}
+	found, err := swagger.Tables(only)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(found) == 0 {
+		log.Fatalf("%s: no tables to generate", *schema_path)
+	}
+
+	if *package_name == "" {
+		*package_name = strings.NewReplacer(".", "_", "-", "_").Replace(swagger.Version())
+	}
+	source, err := generate(swagger, found, *schema_path, *package_name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		fmt.Print(string(source))
+		return
+	}
+	if err := os.WriteFile(*output, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
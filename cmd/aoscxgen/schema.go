@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Swagger is the part of an AOS-CX Swagger 2.0 document used by the generator.
+type Swagger struct {
+	Info struct {
+		Version string `json:"version"`
+	} `json:"info"`
+	BasePath    string              `json:"basePath"`
+	Paths       map[string]PathItem `json:"paths"`
+	Definitions map[string]*Schema  `json:"definitions"`
+}
+
+// PathItem holds the operations of one path.
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Post       *Operation  `json:"post"`
+	Put        *Operation  `json:"put"`
+	Patch      *Operation  `json:"patch"`
+	Delete     *Operation  `json:"delete"`
+}
+
+// Operation is a single method of a path.
+type Operation struct {
+	Parameters []Parameter          `json:"parameters"`
+	Responses  map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or body parameter of an operation.
+type Parameter struct {
+	Name   string  `json:"name"`
+	In     string  `json:"in"`
+	Type   string  `json:"type"`
+	Format string  `json:"format"`
+	Schema *Schema `json:"schema"`
+}
+
+// Response is an operation response.
+type Response struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema of a definition or property.
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	ReadOnly    bool               `json:"readOnly"`
+	Items       *Schema            `json:"items"`
+	Properties  map[string]*Schema `json:"properties"`
+	// AdditionalProperties is either a boolean or a schema.
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+}
+
+// Table is a REST collection that has a path for each of its rows, such as
+// system/vlans and system/vlans/{id}.
+type Table struct {
+	// Path is the collection path relative to the API version, e.g.
+	// "system/interfaces/{name}/ip6_addresses".
+	Path string
+	// Name is the last segment of Path, e.g. "ip6_addresses".
+	Name string
+	// Key is the path parameter naming a row, Parents the path parameters of
+	// the rows the table belongs to, outermost first.
+	Key     Parameter
+	Parents []Parent
+	Schema  *Schema
+
+	Create bool
+	Update string // "patch", "put" or empty
+	Delete bool
+}
+
+// Parent is a path parameter of an enclosing table.
+type Parent struct {
+	Table string
+	Param Parameter
+}
+
+// loadSwagger reads a Swagger document from path.
+func loadSwagger(path string) (*Swagger, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var swagger Swagger
+	if err := json.Unmarshal(contents, &swagger); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &swagger, nil
+}
+
+// Version returns the REST API version of the document, e.g. "v10.09".
+func (s *Swagger) Version() string {
+	if index := strings.Index(s.BasePath, "/v"); index >= 0 {
+		return strings.Trim(s.BasePath[index+1:], "/")
+	}
+	if s.Info.Version != "" {
+		return "v" + strings.TrimPrefix(s.Info.Version, "v")
+	}
+	return ""
+}
+
+// Tables returns the tables of the document in path order. Collections without
+// a GET on their rows are skipped, as are tables not listed in only when only
+// is not empty.
+func (s *Swagger) Tables(only []string) ([]*Table, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var tables []*Table
+	for _, path := range paths {
+		collection := strings.Trim(path, "/")
+		if collection == "" || isParameter(lastSegment(collection)) {
+			continue
+		}
+		item_path, item, ok := s.itemPath(path)
+		if !ok || item.Get == nil {
+			continue
+		}
+		if len(only) > 0 && !contains(only, collection) {
+			continue
+		}
+
+		params := pathParameters(item_path, item)
+		schema, err := s.rowSchema(s.Paths[path], item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item_path, err)
+		}
+
+		table := &Table{
+			Path:   collection,
+			Name:   lastSegment(collection),
+			Key:    params[len(params)-1],
+			Schema: schema,
+			Create: s.Paths[path].Post != nil,
+			Delete: item.Delete != nil,
+		}
+		switch {
+		case item.Patch != nil:
+			table.Update = "patch"
+		case item.Put != nil:
+			table.Update = "put"
+		}
+
+		segments := strings.Split(collection, "/")
+		parent_index := 0
+		for index, segment := range segments {
+			if isParameter(segment) {
+				table.Parents = append(table.Parents, Parent{Table: segments[index-1], Param: params[parent_index]})
+				parent_index++
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// itemPath finds the path of the rows of a collection, e.g. /system/vlans/{id}.
+func (s *Swagger) itemPath(collection string) (string, PathItem, bool) {
+	for path, item := range s.Paths {
+		key, found := strings.CutPrefix(path, collection+"/")
+		if found && !strings.Contains(key, "/") && isParameter(key) {
+			return path, item, true
+		}
+	}
+	return "", PathItem{}, false
+}
+
+// rowSchema resolves the schema of a row from the GET response of its path,
+// or the body of the POST to its collection.
+func (s *Swagger) rowSchema(collection, item PathItem) (*Schema, error) {
+	var schema *Schema
+	if response := item.Get.Responses["200"]; response != nil && response.Schema != nil {
+		schema = response.Schema
+	} else if collection.Post != nil {
+		for _, param := range collection.Post.Parameters {
+			if param.In == "body" && param.Schema != nil {
+				schema = param.Schema
+			}
+		}
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("no schema for the rows")
+	}
+	return s.resolve(schema)
+}
+
+// resolve follows a $ref to its definition.
+func (s *Swagger) resolve(schema *Schema) (*Schema, error) {
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+	definition, ok := s.Definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined reference %s", schema.Ref)
+	}
+	return definition, nil
+}
+
+// pathParameters returns the path parameters of path in order, typed from the
+// parameter declarations of the path and its GET operation.
+func pathParameters(path string, item PathItem) []Parameter {
+	declared := map[string]Parameter{}
+	for _, param := range append(append([]Parameter{}, item.Parameters...), item.Get.Parameters...) {
+		if param.In == "path" {
+			declared[param.Name] = param
+		}
+	}
+
+	var params []Parameter
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if !isParameter(segment) {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		param, ok := declared[name]
+		if !ok {
+			param = Parameter{Name: name, In: "path", Type: "string"}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,59 +2,25 @@ package main
 
 import (
 	"log"
-	"os"
-	"strconv"
 
 	"github.com/felixn-unity/aoscxgo"
 )
 
 func main() {
 	// Get configuration from environment variables
-	hostname := os.Getenv("AOSCX_HOSTNAME")
-	if hostname == "" {
-		log.Fatal("AOSCX_HOSTNAME environment variable is required")
+	client, err := aoscxgo.NewClientFromEnv()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	username := os.Getenv("AOSCX_USERNAME")
-	if username == "" {
-		username = "admin" // Default username
-	}
-
-	password := os.Getenv("AOSCX_PASSWORD")
-	if password == "" {
-		log.Fatal("AOSCX_PASSWORD environment variable is required")
-	}
-
-	// Optional: Certificate verification (defaults to false for development)
-	verifyCert := false
-	if certStr := os.Getenv("AOSCX_VERIFY_CERT"); certStr != "" {
-		if parsed, err := strconv.ParseBool(certStr); err == nil {
-			verifyCert = parsed
-		}
-	}
-
-	// Optional: API version (defaults to v10.09)
-	version := os.Getenv("AOSCX_VERSION")
-	if version == "" {
-		version = "v10.09"
-	}
-
-	sw, err := aoscxgo.Connect(
-		&aoscxgo.Client{
-			Hostname:          hostname,
-			Username:          username,
-			Password:          password,
-			Version:           version,
-			VerifyCertificate: verifyCert,
-		},
-	)
+	sw, err := aoscxgo.Connect(client)
 
 	if err != nil || sw == nil {
 		log.Printf("Failed to login to switch: %s", err)
 		return
 	}
 
-	log.Printf("Successfully connected to switch %s using API version %s", hostname, sw.Version)
+	log.Printf("Successfully connected to switch %s using API version %s", sw.Hostname, sw.Version)
 
 	if 1 == 2 {
 
@@ -107,7 +73,7 @@ func main() {
 			VlanTag:  100,
 		}
 
-		err = portl2.Update(sw, true)
+		err = portl2.Replace(sw)
 
 		if err != nil {
 			log.Printf("Error in updating port xx: %s", err)
@@ -149,7 +115,7 @@ func main() {
 			AdminState:      "up",
 			VlanMode:        "native-untagged",
 			VlanTag:         100,
-			VlanIds:         []interface{}{100, 600},
+			VlanIds:         []int{100, 600},
 			TrunkAllowedAll: true,
 			NativeVlanTag:   true,
 			LacpMode:        "active",
@@ -194,7 +160,7 @@ func main() {
 		err = vlan.Create(sw)
 
 		if err != nil {
-			log.Printf("Error in creating VLAN %s: %s", vlan.VlanId, err)
+			log.Printf("Error in creating VLAN %d: %s", vlan.VlanId, err)
 			return
 		}
 
@@ -215,7 +181,7 @@ func main() {
 		err = vlan.Update(sw)
 
 		if err != nil {
-			log.Printf("Error in updating VLAN %s: %s", vlan.VlanId, err)
+			log.Printf("Error in updating VLAN %d: %s", vlan.VlanId, err)
 			return
 		}
 
@@ -231,7 +197,7 @@ func main() {
 		err = vlandel.Delete(sw)
 
 		if err != nil {
-			log.Printf("Error in creating VLAN %s: %s", vlandel.VlanId, err)
+			log.Printf("Error in creating VLAN %d: %s", vlandel.VlanId, err)
 			return
 		}
 
@@ -0,0 +1,144 @@
+package aoscxgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by NewClientFromEnv and EnvCredentials.
+const (
+	EnvHostname   = "AOSCX_HOSTNAME"
+	EnvUsername   = "AOSCX_USERNAME"
+	EnvPassword   = "AOSCX_PASSWORD"
+	EnvVersion    = "AOSCX_VERSION"
+	EnvVerifyCert = "AOSCX_VERIFY_CERT"
+)
+
+// defaultUsername is used when no username is configured.
+const defaultUsername = "admin"
+
+// CredentialProvider supplies the username and password used to log in to the switch.
+// It is consulted by Connect and again whenever the client has to re-authenticate,
+// so rotated credentials are picked up without rebuilding the Client.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (username string, password string, err error)
+}
+
+// StaticCredentials is a CredentialProvider that always returns the same credentials.
+type StaticCredentials struct {
+	Username string
+	Password string
+}
+
+// Credentials implements CredentialProvider
+func (s StaticCredentials) Credentials(ctx context.Context) (string, string, error) {
+	return s.Username, s.Password, nil
+}
+
+// EnvCredentials is a CredentialProvider that reads the credentials from environment
+// variables each time it is consulted. Empty variable names default to
+// AOSCX_USERNAME and AOSCX_PASSWORD, and an unset username defaults to "admin".
+type EnvCredentials struct {
+	UsernameVar string
+	PasswordVar string
+}
+
+// Credentials implements CredentialProvider
+func (e EnvCredentials) Credentials(ctx context.Context) (string, string, error) {
+	username_var := e.UsernameVar
+	if username_var == "" {
+		username_var = EnvUsername
+	}
+	password_var := e.PasswordVar
+	if password_var == "" {
+		password_var = EnvPassword
+	}
+
+	username := os.Getenv(username_var)
+	if username == "" {
+		username = defaultUsername
+	}
+	password := os.Getenv(password_var)
+	if password == "" {
+		return "", "", fmt.Errorf("%s environment variable is required", password_var)
+	}
+	return username, password, nil
+}
+
+// FileCredentials is a CredentialProvider that reads the credentials from files, such
+// as a mounted Kubernetes secret, each time it is consulted. Trailing newlines are
+// removed. When UsernameFile is empty, Username is used instead, defaulting to "admin".
+type FileCredentials struct {
+	UsernameFile string
+	PasswordFile string
+	Username     string
+}
+
+// Credentials implements CredentialProvider
+func (f FileCredentials) Credentials(ctx context.Context) (string, string, error) {
+	username := f.Username
+	if f.UsernameFile != "" {
+		contents, err := os.ReadFile(f.UsernameFile)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read username file: %w", err)
+		}
+		username = strings.TrimRight(string(contents), "\r\n")
+	}
+	if username == "" {
+		username = defaultUsername
+	}
+
+	contents, err := os.ReadFile(f.PasswordFile)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read password file: %w", err)
+	}
+	return username, strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+// credentials returns the username and password to log in with, from the
+// Credentials provider if one is set and from Username and Password otherwise.
+func (c *Client) credentials(ctx context.Context) (string, string, error) {
+	if c.Credentials == nil {
+		return c.Username, c.Password, nil
+	}
+	username, password, err := c.Credentials.Credentials(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to obtain credentials: %w", err)
+	}
+	return username, password, nil
+}
+
+// NewClientFromEnv creates a Client configured from the AOSCX_HOSTNAME, AOSCX_USERNAME,
+// AOSCX_PASSWORD, AOSCX_VERSION and AOSCX_VERIFY_CERT environment variables. The
+// credentials are read through EnvCredentials, so they are looked up again on every
+// login. The returned Client still has to be passed to Connect.
+func NewClientFromEnv() (*Client, error) {
+	hostname := os.Getenv(EnvHostname)
+	if hostname == "" {
+		return nil, fmt.Errorf("%s environment variable is required", EnvHostname)
+	}
+
+	provider := EnvCredentials{}
+	if _, _, err := provider.Credentials(context.Background()); err != nil {
+		return nil, err
+	}
+
+	verify_cert := false
+	if cert_str := os.Getenv(EnvVerifyCert); cert_str != "" {
+		parsed, err := strconv.ParseBool(cert_str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", EnvVerifyCert, cert_str, err)
+		}
+		verify_cert = parsed
+	}
+
+	return &Client{
+		Hostname:          hostname,
+		Version:           os.Getenv(EnvVersion),
+		Credentials:       provider,
+		VerifyCertificate: verify_cert,
+	}, nil
+}
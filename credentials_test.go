@@ -0,0 +1,221 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// writeSecret writes contents to a file in dir and returns its name.
+func writeSecret(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	username_file := writeSecret(t, dir, "username", "operator\n")
+	password_file := writeSecret(t, dir, "password", "s3cret \r\n")
+	missing_file := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name         string
+		provider     aoscxgo.FileCredentials
+		wantUsername string
+		wantPassword string
+		wantErr      string
+	}{
+		{
+			name:         "username and password files",
+			provider:     aoscxgo.FileCredentials{UsernameFile: username_file, PasswordFile: password_file},
+			wantUsername: "operator",
+			wantPassword: "s3cret ",
+		},
+		{
+			name:         "Username fallback",
+			provider:     aoscxgo.FileCredentials{PasswordFile: password_file, Username: "netops"},
+			wantUsername: "netops",
+			wantPassword: "s3cret ",
+		},
+		{
+			name:         "default username",
+			provider:     aoscxgo.FileCredentials{PasswordFile: password_file},
+			wantUsername: "admin",
+			wantPassword: "s3cret ",
+		},
+		{
+			name:         "username file overrides Username",
+			provider:     aoscxgo.FileCredentials{UsernameFile: username_file, PasswordFile: password_file, Username: "netops"},
+			wantUsername: "operator",
+			wantPassword: "s3cret ",
+		},
+		{
+			name:     "missing password file",
+			provider: aoscxgo.FileCredentials{PasswordFile: missing_file},
+			wantErr:  "unable to read password file",
+		},
+		{
+			name:     "missing username file",
+			provider: aoscxgo.FileCredentials{UsernameFile: missing_file, PasswordFile: password_file},
+			wantErr:  "unable to read username file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := tt.provider.Credentials(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Credentials() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Credentials() error = %v", err)
+			}
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("Credentials() = %q, %q, want %q, %q", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	password_file := writeSecret(t, dir, "password", aoscxtest.DefaultPassword+"\n")
+	client := srv.Client()
+	client.Credentials = aoscxgo.FileCredentials{PasswordFile: password_file}
+	sw, err := aoscxgo.Connect(client)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	srv.SetCredentials(aoscxtest.DefaultUsername, "rotated")
+	srv.ExpireSessions()
+	writeSecret(t, dir, "password", "rotated\n")
+
+	vlan := aoscxgo.Vlan{VlanId: 1}
+	if err := vlan.Get(sw); err != nil {
+		t.Errorf("Get() after rotation error = %v", err)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv(aoscxgo.EnvUsername, "")
+	t.Setenv(aoscxgo.EnvPassword, "")
+	if _, _, err := (aoscxgo.EnvCredentials{}).Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), aoscxgo.EnvPassword) {
+		t.Errorf("Credentials() without a password error = %v, want %s to be required", err, aoscxgo.EnvPassword)
+	}
+
+	t.Setenv(aoscxgo.EnvPassword, "s3cret")
+	username, password, err := aoscxgo.EnvCredentials{}.Credentials(context.Background())
+	if err != nil || username != "admin" || password != "s3cret" {
+		t.Errorf("Credentials() = %q, %q, %v, want admin, s3cret", username, password, err)
+	}
+
+	t.Setenv("SWITCH_USER", "operator")
+	t.Setenv("SWITCH_PASSWORD", "other")
+	username, password, err = aoscxgo.EnvCredentials{UsernameVar: "SWITCH_USER", PasswordVar: "SWITCH_PASSWORD"}.Credentials(context.Background())
+	if err != nil || username != "operator" || password != "other" {
+		t.Errorf("Credentials() with custom variables = %q, %q, %v, want operator, other", username, password, err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantErr    string
+		wantVerify bool
+	}{
+		{
+			name:    "missing hostname",
+			env:     map[string]string{aoscxgo.EnvPassword: "s3cret"},
+			wantErr: aoscxgo.EnvHostname,
+		},
+		{
+			name:    "missing password",
+			env:     map[string]string{aoscxgo.EnvHostname: "switch.example.com"},
+			wantErr: aoscxgo.EnvPassword,
+		},
+		{
+			name:    "invalid verify cert",
+			env:     map[string]string{aoscxgo.EnvHostname: "switch.example.com", aoscxgo.EnvPassword: "s3cret", aoscxgo.EnvVerifyCert: "maybe"},
+			wantErr: aoscxgo.EnvVerifyCert,
+		},
+		{
+			name: "defaults",
+			env:  map[string]string{aoscxgo.EnvHostname: "switch.example.com", aoscxgo.EnvPassword: "s3cret"},
+		},
+		{
+			name:       "verify cert",
+			env:        map[string]string{aoscxgo.EnvHostname: "switch.example.com", aoscxgo.EnvPassword: "s3cret", aoscxgo.EnvVerifyCert: "true", aoscxgo.EnvVersion: "v10.09"},
+			wantVerify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{aoscxgo.EnvHostname, aoscxgo.EnvUsername, aoscxgo.EnvPassword, aoscxgo.EnvVersion, aoscxgo.EnvVerifyCert} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			client, err := aoscxgo.NewClientFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewClientFromEnv() error = %v, want it to mention %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClientFromEnv() error = %v", err)
+			}
+			if client.Hostname != "switch.example.com" || client.Version != tt.env[aoscxgo.EnvVersion] || client.VerifyCertificate != tt.wantVerify {
+				t.Errorf("NewClientFromEnv() = %+v", client)
+			}
+			if client.Password != "" {
+				t.Error("NewClientFromEnv() stored the password on the Client")
+			}
+			if _, ok := client.Credentials.(aoscxgo.EnvCredentials); !ok {
+				t.Errorf("Credentials = %T, want EnvCredentials", client.Credentials)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnvConnect(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+	template := srv.Client()
+
+	t.Setenv(aoscxgo.EnvHostname, template.Hostname)
+	t.Setenv(aoscxgo.EnvUsername, aoscxtest.DefaultUsername)
+	t.Setenv(aoscxgo.EnvPassword, aoscxtest.DefaultPassword)
+	t.Setenv(aoscxgo.EnvVersion, "")
+	t.Setenv(aoscxgo.EnvVerifyCert, "")
+
+	client, err := aoscxgo.NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Port = template.Port
+	client.Transport = template.Transport
+	if _, err := aoscxgo.Connect(client); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if srv.Sessions() != 1 {
+		t.Errorf("Sessions() = %d, want 1", srv.Sessions())
+	}
+}
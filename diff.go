@@ -0,0 +1,166 @@
+package aoscxgo
+
+import (
+	"context"
+	"reflect"
+	"sort"
+)
+
+// FieldMask lists attributes, by their AOS-CX names as in Difference, that
+// Update sets even when the field holding them has its zero value. Without it
+// a zero field, such as an empty Description, leaves the attribute on the
+// switch as it is:
+//
+//	vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink", Mask: aoscxgo.FieldMask{"description"}}
+//	err = vlan.Update(sw) // clears the description
+type FieldMask []string
+
+// Has reports whether attribute is in the mask.
+func (m FieldMask) Has(attribute string) bool {
+	for _, name := range m {
+		if name == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// stateful is implemented by the resources whose Update only sends the
+// attributes that differ from the switch.
+type stateful interface {
+	Resource
+	// state returns the attributes the resource manages, keyed by AOS-CX
+	// name. The desired state holds the values Update writes, normalized so
+	// that they equal the values read back once applied, and leaves out the
+	// attributes whose field is unset and not in the Mask. Otherwise it is the
+	// state of a resource loaded from the switch, with every attribute.
+	state(desired bool) map[string]interface{}
+	// writableState reads the state of the resource from the switch with
+	// ?selector=writable, without changing the receiver.
+	writableState(ctx context.Context, c *Client) (map[string]interface{}, error)
+}
+
+// attributeState collects the attributes of a resource for state.
+type attributeState struct {
+	values  map[string]interface{}
+	desired bool
+	mask    FieldMask
+}
+
+func newAttributeState(desired bool, mask FieldMask) *attributeState {
+	return &attributeState{values: map[string]interface{}{}, desired: desired, mask: mask}
+}
+
+// add records an attribute. In a desired state it is only recorded when set
+// is true or the attribute is in the mask.
+func (s *attributeState) add(attribute string, value interface{}, set bool) {
+	if s.desired && !set && !s.mask.Has(attribute) {
+		return
+	}
+	s.values[attribute] = value
+}
+
+// changes reads the writable state of r from the switch and returns the
+// attributes of its desired state that differ.
+func changes(ctx context.Context, c *Client, r stateful) ([]Difference, error) {
+	current, err := r.writableState(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return differences(current, r.state(true)), nil
+}
+
+// changedBody keeps the attributes of a request body that are listed in
+// changed. user_config carries the admin state and is kept along with admin.
+func changedBody(body map[string]interface{}, changed []Difference) map[string]interface{} {
+	kept := map[string]interface{}{}
+	for _, difference := range changed {
+		if value, ok := body[difference.Attribute]; ok {
+			kept[difference.Attribute] = value
+		}
+		if user_config, ok := body["user_config"]; ok && difference.Attribute == "admin" {
+			kept["user_config"] = user_config
+		}
+	}
+	return kept
+}
+
+// hasDifference reports whether one of attributes is in differences.
+func hasDifference(differences []Difference, attributes ...string) bool {
+	for _, difference := range differences {
+		for _, attribute := range attributes {
+			if difference.Attribute == attribute {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// differences compares two states attribute by attribute. Attributes missing
+// from desired are not managed and only reported when desired is nil.
+func differences(current, desired map[string]interface{}) []Difference {
+	var changed []Difference
+	if desired == nil {
+		for attribute, value := range current {
+			changed = append(changed, Difference{Attribute: attribute, Current: value})
+		}
+	}
+	for attribute, value := range desired {
+		current_value, found := current[attribute]
+		if !found || !reflect.DeepEqual(current_value, value) {
+			changed = append(changed, Difference{Attribute: attribute, Current: current_value, Desired: value})
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Attribute < changed[j].Attribute })
+	return changed
+}
+
+// addVlanState adds the VLAN attributes of a port or LAG. A desired state gets
+// the defaults applied by Update: an access port without a VlanTag is placed
+// in VLAN 1, and a trunk with native VLAN 1 has no vlan_tag.
+func (s *attributeState) addVlanState(vlan_mode string, vlan_tag int, vlan_ids []int, trunk_allowed_all bool, set bool) {
+	trunks := []int{}
+	if !trunk_allowed_all {
+		trunks = append(trunks, vlan_ids...)
+		sort.Ints(trunks)
+	}
+
+	if s.desired && vlan_mode == "access" && vlan_tag == 0 {
+		vlan_tag = 1
+	} else if s.desired && vlan_mode != "access" && vlan_tag == 1 {
+		vlan_tag = 0
+	}
+
+	s.add("vlan_mode", vlan_mode, set)
+	s.add("vlan_tag", vlan_tag, set)
+	if !s.desired || vlan_mode != "access" {
+		s.add("vlan_trunks", trunks, set)
+	}
+}
+
+// addRoutedState adds the addressing attributes of a routed interface. Ipv4
+// holds the primary address followed by the secondary ones, and an empty Vrf
+// is the default VRF, which a desired state always sets.
+func (s *attributeState) addRoutedState(vrf string, ipv4, ipv6 []string) {
+	if s.desired && vrf == "" {
+		vrf = "default"
+	}
+	s.add("vrf", vrf, true)
+
+	primary := ""
+	secondary := []string{}
+	for index, address := range ipv4 {
+		if index == 0 {
+			primary = address
+		} else {
+			secondary = append(secondary, address)
+		}
+	}
+	s.add("ip4_address", primary, len(ipv4) > 0)
+	s.add("ip4_address_secondary", secondary, len(ipv4) > 0 || s.mask.Has("ip4_address"))
+
+	ip6_addresses := append([]string{}, ipv6...)
+	sort.Strings(ip6_addresses)
+	s.add("ip6_addresses", ip6_addresses, len(ipv6) > 0)
+}
@@ -0,0 +1,119 @@
+package aoscxgo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+func TestUpdateSendsChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing aoscxgo.Resource
+		update   aoscxgo.Resource
+		want     []string
+	}{
+		{
+			name:     "Vlan description",
+			existing: &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "up"},
+			update:   &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to edge"},
+			want:     []string{"description"},
+		},
+		{
+			name:     "Vlan unchanged",
+			existing: &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core"},
+			update:   &aoscxgo.Vlan{VlanId: 100, Name: "uplink"},
+		},
+		{
+			name:     "Vlan masked description",
+			existing: &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core"},
+			update:   &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Mask: aoscxgo.FieldMask{"description"}},
+			want:     []string{"description"},
+		},
+		{
+			name:     "Interface admin state",
+			existing: &aoscxgo.Interface{Name: "1/1/9", Description: "server", AdminState: "down"},
+			update:   &aoscxgo.Interface{Name: "1/1/9", AdminState: "up"},
+			want:     []string{"admin", "user_config"},
+		},
+		{
+			name:     "L2Interface description",
+			existing: &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, VlanTag: 200},
+			update:   &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, Description: "access port"},
+			want:     []string{"description"},
+		},
+		{
+			name:     "L2Interface native VLAN",
+			existing: &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, VlanMode: "trunk", VlanIds: []int{200}},
+			update:   &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, VlanMode: "trunk", VlanTag: 200, VlanIds: []int{200}},
+			want:     []string{"vlan_tag"},
+		},
+		{
+			name:     "L3Interface unchanged addresses",
+			existing: &aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/3", AdminState: "up"}, Ipv4: []string{"10.0.0.1/24"}},
+			update:   &aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/3", AdminState: "up"}, Description: "routed"},
+			want:     []string{"description"},
+		},
+		{
+			name:     "LagInterface LACP mode",
+			existing: &aoscxgo.LagInterface{Name: "lag10", AdminState: "up", VlanTag: 200, LacpMode: "passive"},
+			update:   &aoscxgo.LagInterface{Name: "lag10", AdminState: "up", LacpMode: "active"},
+			want:     []string{"lacp"},
+		},
+		{
+			name:     "VlanInterface VRF",
+			existing: &aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 200}, Description: "svi", Ipv4: []string{"10.2.0.1/24"}},
+			update:   &aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 200}, Vrf: "mgmt"},
+			want:     []string{"vrf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 200)
+			if err := tt.existing.Create(sw); err != nil {
+				t.Fatal(err)
+			}
+			srv.ResetRequests()
+
+			if err := tt.update.Update(sw); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			var patches []aoscxtest.Request
+			for _, req := range srv.Requests() {
+				if req.Method != http.MethodGet {
+					patches = append(patches, req)
+				}
+			}
+			if tt.want == nil {
+				if len(patches) != 0 {
+					t.Errorf("Update() sent %v, want nothing", patches)
+				}
+				return
+			}
+			if len(patches) != 1 || patches[0].Method != http.MethodPatch {
+				t.Fatalf("Update() sent %v, want a single PATCH", patches)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(patches[0].Body), &body); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for key := range body {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PATCH attributes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
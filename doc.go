@@ -57,5 +57,20 @@ Each API resource will have the following functions (exceptions may vary):
   - Get
   - GetStatus
   - Delete
+  - Ensure and EnsureAbsent
+  - Kind, Key, URI and Exists (see Resource)
+
+Update reads the object first and only sends the attributes that changed.
+Empty fields are left as they are on the switch; see FieldMask to clear them.
+
+Every call that talks to the switch also has a Context variant (CreateContext,
+UpdateContext, GetContext, DeleteContext, ConnectContext, ...) that accepts a
+context.Context, so that callers can set deadlines or cancel long-running
+operations such as the FullConfig dryrun polling loop:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = vlan100.CreateContext(ctx, sw)
 */
 package aoscxgo
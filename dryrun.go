@@ -0,0 +1,131 @@
+package aoscxgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PlannedOperation is a mutating request captured instead of being sent to the
+// switch while Client.DryRun is set.
+type PlannedOperation struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Body is the JSON request body. Bodies that are not JSON, such as the CLI
+	// configuration sent by FullConfig, are stored as a JSON string.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// String formats the operation as a single line for review.
+func (p PlannedOperation) String() string {
+	if len(p.Body) == 0 {
+		return p.Method + " " + p.URL
+	}
+	return p.Method + " " + p.URL + " " + string(p.Body)
+}
+
+// Plan returns the operations captured while DryRun was set, in the order they
+// would have been sent.
+func (c *Client) Plan() []PlannedOperation {
+	c.planMu.Lock()
+	defer c.planMu.Unlock()
+	return append([]PlannedOperation(nil), c.plan...)
+}
+
+// ResetPlan discards the captured operations.
+func (c *Client) ResetPlan() {
+	c.planMu.Lock()
+	defer c.planMu.Unlock()
+	c.plan = nil
+}
+
+// executeMutation sends a POST, PUT, PATCH or DELETE request and reports it to
+// the AuditSink. When DryRun is set the request is captured into the plan instead
+// and answered with the status the switch returns on success. Dryrun validation
+// of a configuration changes nothing, so it is always sent and never audited.
+func executeMutation(client *Client, req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("dryrun") == "validate" {
+		return executeRequest(client, req)
+	}
+	if !client.DryRun {
+		start := time.Now()
+		res, err := executeRequest(client, req)
+		client.audit(req, res, err, start)
+		return res, err
+	}
+
+	operation, err := planOperation(req)
+	if err != nil {
+		return nil, err
+	}
+	client.planMu.Lock()
+	client.plan = append(client.plan, operation)
+	client.planMu.Unlock()
+
+	client.logger().Info("dry run, request not sent", "method", req.Method, "path", req.URL.Path)
+	return plannedResponse(req), nil
+}
+
+// planOperation captures method, URL and body of req.
+func planOperation(req *http.Request) (PlannedOperation, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return PlannedOperation{}, err
+	}
+	return PlannedOperation{Method: req.Method, URL: req.URL.String(), Body: body}, nil
+}
+
+// requestBody returns the body of req as JSON without consuming it. Bodies
+// that are not JSON are returned as a JSON string.
+func requestBody(req *http.Request) (json.RawMessage, error) {
+	if req.GetBody == nil {
+		return nil, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	defer body.Close()
+	contents, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	switch {
+	case len(contents) == 0:
+		return nil, nil
+	case json.Valid(contents):
+		return contents, nil
+	}
+	encoded, _ := json.Marshal(string(contents))
+	return encoded, nil
+}
+
+// plannedResponse returns the response the switch sends when req succeeds.
+func plannedResponse(req *http.Request) *http.Response {
+	status := http.StatusNoContent
+	switch req.Method {
+	case http.MethodPost:
+		status = http.StatusCreated
+		if req.URL.Query().Has("dryrun") {
+			status = http.StatusAccepted
+		}
+	case http.MethodPut:
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}
+}
@@ -0,0 +1,192 @@
+package aoscxgo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(sw *aoscxgo.Client) error
+		wantPlan []string
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Vlan.Create",
+			run: func(sw *aoscxgo.Client) error {
+				vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink"}
+				return vlan.Create(sw)
+			},
+			wantPlan: []string{"POST /system/vlans"},
+			wantBody: map[string]interface{}{"id": float64(100), "name": "uplink", "type": "static"},
+		},
+		{
+			name: "Vlan.Delete",
+			run: func(sw *aoscxgo.Client) error {
+				vlan := aoscxgo.Vlan{VlanId: 200}
+				return vlan.Delete(sw)
+			},
+			wantPlan: []string{"DELETE /system/vlans/200"},
+		},
+		{
+			name: "LagInterface.Update",
+			run: func(sw *aoscxgo.Client) error {
+				lag := aoscxgo.LagInterface{Name: "lag1", AdminState: "up", Description: "planned"}
+				return lag.Update(sw)
+			},
+			wantPlan: []string{"PATCH /system/interfaces/lag1"},
+			wantBody: map[string]interface{}{"admin": "up", "description": "planned"},
+		},
+		{
+			name: "L2Interface.Delete",
+			run: func(sw *aoscxgo.Client) error {
+				l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1"}}
+				return l2.Delete(sw)
+			},
+			wantPlan: []string{"PUT /system/interfaces/1%2F1%2F1"},
+			wantBody: map[string]interface{}{},
+		},
+		{
+			name: "L2Interface.Create",
+			run: func(sw *aoscxgo.Client) error {
+				l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, VlanTag: 200}
+				return l2.Create(sw)
+			},
+			wantPlan: []string{"PATCH /system/interfaces/1%2F1%2F2"},
+			wantBody: map[string]interface{}{"vlan_mode": "access", "routing": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 200)
+			lag := aoscxgo.LagInterface{Name: "lag1", AdminState: "down"}
+			if err := lag.Create(sw); err != nil {
+				t.Fatal(err)
+			}
+			before_vlans, _ := srv.Vlan(200)
+			before_lag, _ := srv.Interface("lag1")
+			srv.ResetRequests()
+
+			sw.DryRun = true
+			if err := tt.run(sw); err != nil {
+				t.Fatalf("dry run error = %v", err)
+			}
+
+			for _, req := range srv.Requests() {
+				if req.Method != http.MethodGet {
+					t.Errorf("%s %s sent to the switch during dry run", req.Method, req.Path)
+				}
+			}
+			if after, _ := srv.Vlan(200); len(after) != len(before_vlans) {
+				t.Errorf("VLAN 200 changed during dry run: %v", after)
+			}
+			if after, _ := srv.Interface("lag1"); after["description"] != before_lag["description"] {
+				t.Errorf("lag1 changed during dry run: %v", after)
+			}
+
+			plan := sw.Plan()
+			var got []string
+			for _, operation := range plan {
+				got = append(got, operation.Method+" "+strings.TrimPrefix(operation.URL, srv.URL+"/rest/"+sw.Version))
+			}
+			if len(tt.wantPlan) > len(got) || strings.Join(got[len(got)-len(tt.wantPlan):], ",") != strings.Join(tt.wantPlan, ",") {
+				t.Fatalf("Plan() = %v, want it to end with %v", got, tt.wantPlan)
+			}
+
+			if tt.wantBody != nil {
+				var body map[string]interface{}
+				if err := json.Unmarshal(plan[len(plan)-1].Body, &body); err != nil {
+					t.Fatalf("planned body %q: %v", plan[len(plan)-1].Body, err)
+				}
+				for key, want := range tt.wantBody {
+					if body[key] != want {
+						t.Errorf("planned body[%s] = %v, want %v", key, body[key], want)
+					}
+				}
+			}
+
+			sw.ResetPlan()
+			if len(sw.Plan()) != 0 {
+				t.Error("ResetPlan() left operations")
+			}
+		})
+	}
+}
+
+func TestDryRunFullConfig(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.SetRunningConfig("hostname original\n")
+	sw.DryRun = true
+
+	filename := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(filename, []byte("hostname planned\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := aoscxgo.FullConfig{FileName: filename}
+	if _, err := fc.Create(sw); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := srv.RunningConfig(); got != "hostname original\n" {
+		t.Errorf("running config changed during dry run: %q", got)
+	}
+
+	// Validation is sent, the apply is planned
+	validated, polls := false, 0
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodPost && req.Query == "dryrun=validate" {
+			validated = true
+		}
+		if req.Method == http.MethodGet && req.Query == "dryrun" {
+			polls++
+		}
+	}
+	if !validated {
+		t.Error("configuration was not validated during dry run")
+	}
+	if polls != 1 {
+		t.Errorf("dryrun result polled %d times, want only for the validation", polls)
+	}
+
+	plan := sw.Plan()
+	if len(plan) != 1 || plan[0].Method != http.MethodPost || !strings.HasSuffix(plan[0].URL, "?dryrun=apply") {
+		t.Fatalf("Plan() = %v", plan)
+	}
+	var config string
+	if err := json.Unmarshal(plan[0].Body, &config); err != nil || config != "hostname planned\n" {
+		t.Errorf("planned body = %s, want the configuration as a JSON string", plan[0].Body)
+	}
+}
+
+func TestDryRunApplyConfig(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.SetRunningConfig("hostname original\n")
+	sw.DryRun = true
+	srv.ResetRequests()
+
+	fc := aoscxgo.FullConfig{}
+	res, body, err := fc.ApplyConfig(sw, "hostname planned\n")
+	if err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if res.StatusCode != http.StatusAccepted || body["state"] != "success" {
+		t.Errorf("ApplyConfig() = %d, %v, want the planned response", res.StatusCode, body)
+	}
+
+	// Nothing was applied, so the dryrun result must not be polled
+	for _, req := range srv.Requests() {
+		t.Errorf("%s %s?%s sent to the switch", req.Method, req.Path, req.Query)
+	}
+	if plan := sw.Plan(); len(plan) != 1 || !strings.HasSuffix(plan[0].URL, "?dryrun=apply") {
+		t.Errorf("Plan() = %v, want the apply", plan)
+	}
+}
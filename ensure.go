@@ -0,0 +1,133 @@
+package aoscxgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// EnsureAction describes what Ensure or EnsureAbsent did to reach the wanted state.
+type EnsureAction string
+
+// Actions reported in an EnsureResult.
+const (
+	EnsureCreated   EnsureAction = "created"
+	EnsureUpdated   EnsureAction = "updated"
+	EnsureDeleted   EnsureAction = "deleted"
+	EnsureUnchanged EnsureAction = "unchanged"
+)
+
+// Difference is an attribute whose value on the switch did not match the
+// wanted value. Attributes use the AOS-CX names, e.g. "vlan_tag", and values
+// are normalized: VLAN references are IDs and lists of addresses are sorted.
+// Current is nil when the object did not exist, Desired is nil when the
+// attribute was removed.
+type Difference struct {
+	Attribute string
+	Current   interface{}
+	Desired   interface{}
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %v -> %v", d.Attribute, d.Current, d.Desired)
+}
+
+// EnsureResult is returned by Ensure and EnsureAbsent.
+type EnsureResult struct {
+	Action EnsureAction
+	// Differences lists the attributes that differed, ordered by name. It is
+	// empty when the Action is EnsureUnchanged.
+	Differences []Difference
+}
+
+// Changed reports whether anything was sent to the switch.
+func (r EnsureResult) Changed() bool {
+	return r.Action != EnsureUnchanged
+}
+
+// ensure creates desired when it is missing, or updates it when its state
+// differs from the switch.
+func ensure(ctx context.Context, c *Client, desired stateful) (EnsureResult, error) {
+	current, err := desired.writableState(ctx, c)
+	if errors.Is(err, ErrNotFound) {
+		if err := desired.CreateContext(ctx, c); err != nil {
+			return EnsureResult{}, err
+		}
+		return EnsureResult{Action: EnsureCreated, Differences: differences(nil, desired.state(true))}, nil
+	}
+	if err != nil {
+		return EnsureResult{}, err
+	}
+
+	changed := differences(current, desired.state(true))
+	if len(changed) == 0 {
+		return EnsureResult{Action: EnsureUnchanged}, nil
+	}
+	if err := desired.UpdateContext(ctx, c); err != nil {
+		return EnsureResult{}, err
+	}
+	return EnsureResult{Action: EnsureUpdated, Differences: changed}, nil
+}
+
+// ensureAbsent deletes r if it exists.
+func ensureAbsent(ctx context.Context, c *Client, r Resource) (EnsureResult, error) {
+	found, err := r.ExistsContext(ctx, c)
+	if err != nil {
+		return EnsureResult{}, err
+	}
+	if !found {
+		return EnsureResult{Action: EnsureUnchanged}, nil
+	}
+	if err := r.DeleteContext(ctx, c); err != nil {
+		return EnsureResult{}, err
+	}
+	return EnsureResult{Action: EnsureDeleted}, nil
+}
+
+// ensureDefault resets the configuration of a port that cannot be deleted.
+// The port is left alone when its state holds no configuration, which is the
+// state Delete puts it in.
+func ensureDefault(ctx context.Context, c *Client, r stateful) (EnsureResult, error) {
+	current, err := r.writableState(ctx, c)
+	if err != nil {
+		return EnsureResult{}, err
+	}
+	configured := configuredState(current)
+	if len(configured) == 0 {
+		return EnsureResult{Action: EnsureUnchanged}, nil
+	}
+	if err := r.DeleteContext(ctx, c); err != nil {
+		return EnsureResult{}, err
+	}
+	return EnsureResult{Action: EnsureDeleted, Differences: differences(configured, nil)}, nil
+}
+
+// portDefaults are the values a switch reports for the attributes of a port
+// after Delete has reset it.
+var portDefaults = map[string]interface{}{
+	"admin":     "down",
+	"vlan_mode": "access",
+	"vlan_tag":  1,
+	"vrf":       "default",
+}
+
+// configuredState returns the attributes of a port state that hold
+// configuration, leaving out empty values and the defaults of a reset port.
+// routing is left out too, as its default depends on the platform.
+func configuredState(state map[string]interface{}) map[string]interface{} {
+	configured := map[string]interface{}{}
+	for attribute, value := range state {
+		if attribute == "routing" || reflect.DeepEqual(value, portDefaults[attribute]) {
+			continue
+		}
+		switch reflected := reflect.ValueOf(value); {
+		case !reflected.IsValid() || reflected.IsZero():
+			continue
+		case (reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Map) && reflected.Len() == 0:
+			continue
+		}
+		configured[attribute] = value
+	}
+	return configured
+}
@@ -0,0 +1,167 @@
+package aoscxgo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// attributes returns the attribute names of differences.
+func attributes(differences []aoscxgo.Difference) []string {
+	names := []string{}
+	for _, difference := range differences {
+		names = append(names, difference.Attribute)
+	}
+	return names
+}
+
+func TestVlanEnsure(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+
+	vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink", AdminState: "up"}
+	result, err := vlan.Ensure(sw)
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if result.Action != aoscxgo.EnsureCreated || !reflect.DeepEqual(attributes(result.Differences), []string{"admin", "name"}) {
+		t.Errorf("Ensure() of a missing VLAN = %+v", result)
+	}
+	if _, ok := srv.Vlan(100); !ok {
+		t.Fatal("VLAN 100 was not created")
+	}
+
+	result, err = vlan.Ensure(sw)
+	if err != nil || result.Action != aoscxgo.EnsureUnchanged || result.Changed() || len(result.Differences) != 0 {
+		t.Errorf("Ensure() again = %+v, %v", result, err)
+	}
+
+	vlan.Description = "core uplink"
+	result, err = vlan.Ensure(sw)
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	want := []aoscxgo.Difference{{Attribute: "description", Current: "", Desired: "core uplink"}}
+	if result.Action != aoscxgo.EnsureUpdated || !reflect.DeepEqual(result.Differences, want) {
+		t.Errorf("Ensure() of a changed description = %+v, want %+v", result, want)
+	}
+	if stored, _ := srv.Vlan(100); stored["description"] != "core uplink" {
+		t.Errorf("stored description = %v", stored["description"])
+	}
+
+	for _, want := range []aoscxgo.EnsureAction{aoscxgo.EnsureDeleted, aoscxgo.EnsureUnchanged} {
+		result, err = vlan.EnsureAbsent(sw)
+		if err != nil || result.Action != want {
+			t.Errorf("EnsureAbsent() = %+v, %v, want %s", result, err, want)
+		}
+	}
+	if _, ok := srv.Vlan(100); ok {
+		t.Error("VLAN 100 still exists after EnsureAbsent()")
+	}
+}
+
+// reportDefaults makes the writable state of ports look like a real switch,
+// which reports unset attributes as null or with their default value instead
+// of leaving them out like the fake server.
+func reportDefaults(next http.RoundTripper) http.RoundTripper {
+	defaults := map[string]interface{}{
+		"description": nil, "admin": "down", "routing": true, "vlan_mode": nil, "vlan_tag": nil,
+		"vlan_trunks": map[string]interface{}{}, "vrf": nil, "ip4_address": nil, "ip4_address_secondary": []interface{}{},
+	}
+	return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := next.RoundTrip(req)
+		port := strings.HasPrefix(req.URL.EscapedPath(), "/rest/"+aoscxtest.DefaultVersion+"/system/interfaces/1%2F1%2F")
+		if err != nil || res.StatusCode != http.StatusOK || !port || strings.Contains(req.URL.Path, "ip6_addresses") ||
+			req.URL.Query().Get("selector") != "writable" {
+			return res, err
+		}
+
+		var attributes map[string]interface{}
+		json.NewDecoder(res.Body).Decode(&attributes)
+		res.Body.Close()
+		for attribute, value := range defaults {
+			if _, ok := attributes[attribute]; !ok {
+				attributes[attribute] = value
+			}
+		}
+		body, _ := json.Marshal(attributes)
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		res.Header.Del("Content-Length")
+		return res, nil
+	})
+}
+
+func TestEnsureIdempotent(t *testing.T) {
+	_, sw := newTestSwitch(t)
+	sw.Middleware = append(sw.Middleware, reportDefaults)
+	createVlans(t, sw, 100, 200, 300)
+
+	// Each Ensure gets a fresh copy of the desired state, as a reconciler
+	// would, so that normalization done by Create or Update is not relied on
+	tests := []struct {
+		resource func() aoscxgo.Resource
+		first    aoscxgo.EnsureAction
+	}{
+		{func() aoscxgo.Resource {
+			return &aoscxgo.Interface{Name: "1/1/1", AdminState: "up", Description: "printer"}
+		}, aoscxgo.EnsureUpdated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}}
+		}, aoscxgo.EnsureUpdated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/3", AdminState: "up"}, VlanMode: "trunk", VlanTag: 1, VlanIds: []int{300, 100}}
+		}, aoscxgo.EnsureUpdated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/4", AdminState: "up"}, Ipv4: []string{"10.0.4.1/24", "10.0.5.1/24"}, Ipv6: []string{"2001:db8:4::1/64", "2001:db8:3::1/64"}}
+		}, aoscxgo.EnsureUpdated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.LagInterface{Name: "lag10", AdminState: "up", LacpMode: "active", VlanMode: "native-untagged", VlanTag: 200, VlanIds: []int{200, 100}}
+		}, aoscxgo.EnsureCreated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.LagInterface{Name: "lag20", AdminState: "up", VlanMode: "trunk", VlanTag: 100, VlanIds: []int{300, 100}}
+		}, aoscxgo.EnsureCreated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.LagInterface{Name: "lag30", AdminState: "up", VlanMode: "trunk", VlanTag: 200, NativeVlanTag: true, VlanIds: []int{200}}
+		}, aoscxgo.EnsureCreated},
+		{func() aoscxgo.Resource {
+			return &aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 200, AdminState: "up"}, Description: "servers", Ipv4: []string{"10.0.200.1/24"}, Vrf: "default"}
+		}, aoscxgo.EnsureCreated},
+	}
+
+	for _, tt := range tests {
+		resource := tt.resource()
+		t.Run(resource.Kind()+" "+resource.Key(), func(t *testing.T) {
+			result, err := tt.resource().Ensure(sw)
+			if err != nil {
+				t.Fatalf("Ensure() error = %v", err)
+			}
+			if result.Action != tt.first || len(result.Differences) == 0 {
+				t.Errorf("Ensure() = %+v, want %s", result, tt.first)
+			}
+			for _, difference := range result.Differences {
+				if difference.Desired == "trunk" {
+					t.Errorf("Ensure() difference %s, want the native vlan_mode", difference)
+				}
+			}
+
+			result, err = tt.resource().Ensure(sw)
+			if err != nil || result.Changed() {
+				t.Errorf("Ensure() again = %+v, %v, want unchanged", result, err)
+			}
+
+			for _, want := range []aoscxgo.EnsureAction{aoscxgo.EnsureDeleted, aoscxgo.EnsureUnchanged} {
+				result, err = tt.resource().EnsureAbsent(sw)
+				if err != nil || result.Action != want {
+					t.Errorf("EnsureAbsent() = %+v, %v, want %s", result, err, want)
+				}
+			}
+		})
+	}
+}
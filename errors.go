@@ -0,0 +1,194 @@
+package aoscxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors that can be matched with errors.Is against any error
+// returned by this package.
+var (
+	// ErrNotFound is returned when the requested object does not exist on the switch.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the object being created already exists.
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is returned when the session is missing, expired or lacks permission.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrValidation is returned when a value is rejected, either locally or by the switch.
+	ErrValidation = errors.New("validation error")
+	// ErrDependency is returned when an operation depends on, or is blocked by, another object
+	// (for example deleting a VLAN that still has a VlanInterface).
+	ErrDependency = errors.New("dependency error")
+	// ErrSessionLimit is returned when login fails because the user already
+	// holds the maximum number of concurrent REST sessions on the switch.
+	ErrSessionLimit = errors.New("session limit reached")
+)
+
+// maxErrorMessage caps how much of a response body is kept in an APIError.
+const maxErrorMessage = 1024
+
+// RequestError represents a custom error for HTTP requests
+type RequestError struct {
+	StatusCode string
+	Err        error
+}
+
+// Error implements the error interface
+func (r *RequestError) Error() string {
+	return fmt.Sprintf("Status: %s, Error: %v", r.StatusCode, r.Err)
+}
+
+// Unwrap returns the underlying error so RequestError works with errors.Is and errors.As
+func (r *RequestError) Unwrap() error {
+	return r.Err
+}
+
+// APIError represents an unexpected HTTP response returned by the switch.
+type APIError struct {
+	// Op describes the operation that failed, e.g. "Create Error".
+	Op string
+	// Method and URL of the request that failed.
+	Method string
+	URL    string
+	// StatusCode is the numeric HTTP status, Status the full status line.
+	StatusCode int
+	Status     string
+	// Message is the error reported by AOS-CX in the response body, if any.
+	Message string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %s %s returned %s", e.Op, e.Method, e.URL, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is maps the HTTP status of the response onto the package sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || e.alreadyExists()
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrValidation:
+		return (e.StatusCode == http.StatusBadRequest && !e.alreadyExists() && !e.dependency()) ||
+			e.StatusCode == http.StatusUnprocessableEntity
+	case ErrDependency:
+		return e.dependency()
+	}
+	return false
+}
+
+// alreadyExists reports whether the switch rejected a create because the object exists.
+// AOS-CX answers duplicate POSTs with 400 rather than 409 on most firmware.
+func (e *APIError) alreadyExists() bool {
+	return e.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(e.Message), "already exists")
+}
+
+// dependency reports whether the switch rejected the change because of another object,
+// such as deleting a VLAN or VRF that is still in use or referenced by a port.
+// AOS-CX answers these with 400, or 409 on some firmware, and names the reference.
+func (e *APIError) dependency() bool {
+	if e.StatusCode != http.StatusBadRequest && e.StatusCode != http.StatusConflict {
+		return false
+	}
+	message := strings.ToLower(e.Message)
+	for _, phrase := range []string{"in use", "is referenced", "referenced by", "used by", "depends on", "dependency", "dependent"} {
+		if strings.Contains(message, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionLimitError is returned by Connect when the switch refuses the login
+// because the concurrent REST session limit has been reached. Share one Client
+// per switch, or log out unused sessions, to stay below the limit.
+type SessionLimitError struct {
+	*APIError
+}
+
+// Error implements the error interface
+func (e *SessionLimitError) Error() string {
+	return "REST session limit reached: " + e.APIError.Error()
+}
+
+// Is reports ErrSessionLimit in addition to the sentinels matched by the APIError.
+func (e *SessionLimitError) Is(target error) bool {
+	return target == ErrSessionLimit
+}
+
+// Unwrap returns the underlying APIError
+func (e *SessionLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// sessionLimitReached reports whether a failed login was caused by the session limit.
+func sessionLimitReached(e *APIError) bool {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "session limit") ||
+		strings.Contains(message, "maximum number of") ||
+		strings.Contains(message, "too many sessions")
+}
+
+// newAPIError builds an APIError from res, consuming and closing the response body.
+func newAPIError(op string, res *http.Response) *APIError {
+	api_err := &APIError{
+		Op:         op,
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+	}
+	if res.Request != nil {
+		api_err.Method = res.Request.Method
+		api_err.URL = res.Request.URL.Redacted()
+	}
+	if res.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorMessage))
+		res.Body.Close()
+		api_err.Message = parseErrorMessage(body)
+	}
+	return api_err
+}
+
+// parseErrorMessage extracts the error text from an AOS-CX error body, which is
+// either a JSON object with a message field or plain text.
+func parseErrorMessage(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return ""
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		for _, key := range []string{"message", "error", "errors"} {
+			if value, ok := decoded[key]; ok && value != nil {
+				if str, ok := value.(string); ok {
+					return str
+				}
+				encoded, _ := json.Marshal(value)
+				return string(encoded)
+			}
+		}
+		return text
+	}
+
+	// Plain text bodies often repeat the status line before the actual reason
+	lines := strings.Split(text, "\n")
+	for index := range lines {
+		lines[index] = strings.TrimSpace(lines[index])
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,105 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// answering answers every request of method with status and an AOS-CX error body.
+func answering(method string, status int, body string) aoscxgo.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != method {
+				return next.RoundTrip(req)
+			}
+			return &http.Response{
+				Status:     http.StatusText(status),
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []error
+		notWant []error
+	}{
+		{
+			name:    "VLAN in use",
+			status:  http.StatusBadRequest,
+			body:    `{"message": "VLAN 100 is in use by interface 1/1/1"}`,
+			want:    []error{aoscxgo.ErrDependency},
+			notWant: []error{aoscxgo.ErrValidation, aoscxgo.ErrConflict},
+		},
+		{
+			name:    "referenced object",
+			status:  http.StatusConflict,
+			body:    `{"message": "Object is referenced by /rest/v10.09/system/interfaces/vlan100"}`,
+			want:    []error{aoscxgo.ErrDependency, aoscxgo.ErrConflict},
+			notWant: []error{aoscxgo.ErrValidation},
+		},
+		{
+			name:    "plain text dependency",
+			status:  http.StatusBadRequest,
+			body:    "Bad Request\nCannot delete VRF red: it is used by 2 interfaces",
+			want:    []error{aoscxgo.ErrDependency},
+			notWant: []error{aoscxgo.ErrValidation},
+		},
+		{
+			name:    "invalid value",
+			status:  http.StatusBadRequest,
+			body:    `{"message": "Invalid or missing attribute: name"}`,
+			want:    []error{aoscxgo.ErrValidation},
+			notWant: []error{aoscxgo.ErrDependency},
+		},
+		{
+			name:    "already exists",
+			status:  http.StatusBadRequest,
+			body:    `{"message": "Object already exists"}`,
+			want:    []error{aoscxgo.ErrConflict},
+			notWant: []error{aoscxgo.ErrValidation, aoscxgo.ErrDependency},
+		},
+		{
+			name:    "server error mentioning a dependency",
+			status:  http.StatusInternalServerError,
+			body:    `{"message": "dependency check failed"}`,
+			notWant: []error{aoscxgo.ErrDependency, aoscxgo.ErrValidation},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			sw.Middleware = append(sw.Middleware, answering(http.MethodDelete, tt.status, tt.body))
+
+			err := sw.Delete(context.Background(), "system/vlans/100")
+			var api_err *aoscxgo.APIError
+			if !errors.As(err, &api_err) {
+				t.Fatalf("Delete() error = %v, want an *APIError", err)
+			}
+			for _, target := range tt.want {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = false", err, target)
+				}
+			}
+			for _, target := range tt.notWant {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = true", err, target)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package aoscxgo
+
+// Unexported helpers made available to the tests in package aoscxgo_test,
+// which cannot be internal because they import aoscxtest.
+var (
+	CheckIPAddress  = checkIPAddress
+	CheckName       = checkName
+	ConvertErrors   = convert_errors
+	ParseRetryAfter = parseRetryAfter
+)
+
+func (i *Interface) CheckValues() error {
+	return i.checkValues()
+}
+
+func (l *LagInterface) CheckValues() error {
+	return l.checkValues("Create Error")
+}
+
+var ResourceTable = resourceTable
+
+func (c *Client) RestURL() string {
+	return c.restURL()
+}
+
+func (c *Client) ResourceURL(path string, opts *QueryOptions) string {
+	return c.resourceURL(path, opts)
+}
+
+// UnregisterCapability removes capability from the registry.
+func UnregisterCapability(capability Capability) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	// The package delete function shadows the builtin
+	remaining := make(map[Capability]APIVersion, len(capabilities))
+	for registered, minimum := range capabilities {
+		if registered != capability {
+			remaining[registered] = minimum
+		}
+	}
+	capabilities = remaining
+}
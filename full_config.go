@@ -2,10 +2,10 @@ package aoscxgo
 
 import (
 	"bytes"
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"time"
 
@@ -18,15 +18,20 @@ type FullConfig struct {
 	FileName string `json:"filename"`
 	//Hash     hash.Hash `json:"hash"`
 	Config string `json:"config"`
-	uri    string `json:"uri"`
+	uri    string
 }
 
 // Create performs POST to create VLAN configuration on the given Client object.
 func (fc *FullConfig) Create(c *Client) (*http.Response, error) {
+	return fc.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (fc *FullConfig) CreateContext(ctx context.Context, c *Client) (*http.Response, error) {
 	if fc.FileName == "" {
 		return nil, &RequestError{
 			StatusCode: "Missing FileName",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -39,61 +44,67 @@ func (fc *FullConfig) Create(c *Client) (*http.Response, error) {
 		}
 	}
 
-	res, body := fc.ValidateConfig(c, config_str)
+	res, body, err := fc.ValidateConfigContext(ctx, c, config_str)
+	if err != nil {
+		return res, err
+	}
 
 	if body == nil {
-		return res, &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Validation Error"),
-		}
+		return res, newAPIError("Validation Error", res)
 
 	} else if body["state"] == "success" {
-		res2, body2 := fc.ApplyConfig(c, config_str)
-		if body2["state"] != "success" {
-			errors_dict := body2["errors"].([]interface{})
+		res2, body2, err := fc.ApplyConfigContext(ctx, c, config_str)
+		if err != nil {
+			return res2, err
+		}
+		if body2 == nil {
+			return res2, newAPIError("Apply Error", res2)
+		} else if body2["state"] != "success" {
+			errors_dict, _ := body2["errors"].([]interface{})
 			error_str := convert_errors(errors_dict)
 
 			return res2, &RequestError{
 				StatusCode: "Error in applying config error : \n" + error_str,
-				Err:        errors.New("Apply Error"),
+				Err:        fmt.Errorf("Apply Error: %w", ErrValidation),
 			}
 		} else {
-			log.Println("New Config Applied Successfully")
-			fc.Get(c)
-			return res2, nil
+			if c.DryRun {
+				c.logger().Info("new config planned, not applied", "filename", fc.FileName)
+			} else {
+				c.logger().Info("new config applied successfully", "filename", fc.FileName)
+			}
+			return res2, fc.GetContext(ctx, c)
 		}
 	} else if res != nil && body != nil {
-		errors_dict := body["errors"].([]interface{})
-		errors_dict = errors_dict
+		errors_dict, _ := body["errors"].([]interface{})
 		error_str := convert_errors(errors_dict)
 
 		return res, &RequestError{
 			StatusCode: "Error in validating config error : \n" + error_str,
-			Err:        errors.New("Apply Error"),
+			Err:        fmt.Errorf("Apply Error: %w", ErrValidation),
 		}
 
 	}
 
-	return res, &RequestError{
-		StatusCode: res.Status,
-		Err:        errors.New("Validation Error"),
-	}
+	return res, newAPIError("Validation Error", res)
 }
 
 // Get performs GET to retrieve Running configuration for the given Client object.
 func (fc *FullConfig) Get(c *Client) error {
+	return fc.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (fc *FullConfig) GetContext(ctx context.Context, c *Client) error {
 	base_uri := "configs/running-config"
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
-	res, err := getAcceptText(c, url)
+	url := c.restURL() + "/" + base_uri
+	res, err := getAcceptText(ctx, c, url)
 	if err != nil {
 		return err
 	}
 
 	if res.Status != "200 OK" {
-		return &RequestError{
-			StatusCode: res.Status + url,
-			Err:        errors.New("Retrieval Error"),
-		}
+		return newAPIError("Retrieval Error", res)
 	}
 
 	// Read the content
@@ -128,19 +139,25 @@ func (fc *FullConfig) ReadConfigFile(filename string) (string, error) {
 
 }
 
-// Formats the errors provided by dryrun for user
+// Formats the errors provided by dryrun for user. Entries missing the line
+// or message, or of an unexpected type, are reported as they were received
+// rather than dropped.
 func convert_errors(errors_list []interface{}) string {
 	errors_str := ""
-	for _, error_dict := range errors_list {
-		tmp_dict := error_dict.(map[string]interface{})
-		line_num := tmp_dict["line"]
-		line_float := line_num.(float64)
-		line_int := int(line_float)
+	for _, error_entry := range errors_list {
+		tmp_dict, _ := error_entry.(map[string]interface{})
+		line_float, line_ok := tmp_dict["line"].(float64)
+		message, message_ok := tmp_dict["message"].(string)
+		if !line_ok || !message_ok {
+			raw, _ := json.Marshal(error_entry)
+			errors_str += "malformed error | " + string(raw) + "\n"
+			continue
+		}
 		errors_str += "line "
-		line_num_str := fmt.Sprintf("%d", line_int)
+		line_num_str := fmt.Sprintf("%d", int(line_float))
 		errors_str += line_num_str
 		errors_str += " | "
-		errors_str += tmp_dict["message"].(string)
+		errors_str += message
 		errors_str += "\n"
 	}
 	return errors_str
@@ -161,18 +178,20 @@ func (fc *FullConfig) CompareConfig(new_config string) string {
 
 // Compares supplied string Config to stored Object
 func (fc *FullConfig) DownloadConfig(c *Client, filename string) error {
+	return fc.DownloadConfigContext(context.Background(), c, filename)
+}
+
+// DownloadConfigContext is like DownloadConfig but uses ctx for every request made to the switch.
+func (fc *FullConfig) DownloadConfigContext(ctx context.Context, c *Client, filename string) error {
 	base_uri := "configs/running-config"
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
-	res, err := getAcceptText(c, url)
+	url := c.restURL() + "/" + base_uri
+	res, err := getAcceptText(ctx, c, url)
 	if err != nil {
 		return err
 	}
 
 	if res.Status != "200 OK" {
-		return &RequestError{
-			StatusCode: res.Status + url,
-			Err:        errors.New("Retrieval Error"),
-		}
+		return newAPIError("Retrieval Error", res)
 	}
 
 	// Read the content
@@ -185,31 +204,42 @@ func (fc *FullConfig) DownloadConfig(c *Client, filename string) error {
 	// Use the content
 	bodyString := string(bodyBytes)
 
-	err = ioutil.WriteFile(filename, []byte(bodyString), 0644)
-	if err != nil {
-		panic(err)
-	}
-
-	return err
+	return ioutil.WriteFile(filename, []byte(bodyString), 0644)
 }
 
 // Validates supplied CLI configuration as string using dryrun
-func (fc *FullConfig) ValidateConfig(c *Client, config string) (*http.Response, map[string]interface{}) {
+func (fc *FullConfig) ValidateConfig(c *Client, config string) (*http.Response, map[string]interface{}, error) {
+	return fc.ValidateConfigContext(context.Background(), c, config)
+}
+
+// ValidateConfigContext is like ValidateConfig but uses ctx for every request made to the switch
+// and stops polling the dryrun result once ctx is done.
+func (fc *FullConfig) ValidateConfigContext(ctx context.Context, c *Client, config string) (*http.Response, map[string]interface{}, error) {
+	if err := c.requireCapability(CapabilityConfigDryRun); err != nil {
+		return nil, nil, err
+	}
+
 	base_uri := "configs/running-config"
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
+	url := c.restURL() + "/" + base_uri
 	dryrun_url := url + "?dryrun=validate"
 
 	json_body := bytes.NewBufferString(config)
 
-	res := post(c, dryrun_url, json_body)
+	res, err := post(ctx, c, dryrun_url, json_body)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if res.Status != "200 OK" && res.Status != "202 Accepted" {
-		return res, nil
+		return res, nil, nil
 	}
 
 	dryrun_url = url + "?dryrun"
 
-	res2, body := get(c, dryrun_url)
+	res2, body, err := get(ctx, c, dryrun_url)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	iterations := 10
 
@@ -218,29 +248,58 @@ func (fc *FullConfig) ValidateConfig(c *Client, config string) (*http.Response,
 		if body["state"] == "success" || body["state"] == "error" {
 			break
 		}
-		time.Sleep(2 * time.Second)
-		res2, body = get(c, dryrun_url)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return res2, body, err
+		}
+		res2, body, err = get(ctx, c, dryrun_url)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return res2, body
+	return res2, body, nil
+}
+
+// Applies supplied CLI configuration as string using dryrun
+func (fc *FullConfig) ApplyConfig(c *Client, config string) (*http.Response, map[string]interface{}, error) {
+	return fc.ApplyConfigContext(context.Background(), c, config)
 }
 
-func (fc *FullConfig) ApplyConfig(c *Client, config string) (*http.Response, map[string]interface{}) {
+// ApplyConfigContext is like ApplyConfig but uses ctx for every request made to the switch
+// and stops polling the dryrun result once ctx is done. When the client is in DryRun the
+// apply is only planned, and the planned response is returned with a successful state.
+func (fc *FullConfig) ApplyConfigContext(ctx context.Context, c *Client, config string) (*http.Response, map[string]interface{}, error) {
+	ctx = withAuditTarget(ctx, "apply", "FullConfig", "")
+	if err := c.requireCapability(CapabilityConfigDryRun); err != nil {
+		return nil, nil, err
+	}
+
 	base_uri := "configs/running-config"
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
+	url := c.restURL() + "/" + base_uri
 	dryrun_url := url + "?dryrun=apply"
 
 	json_body := bytes.NewBufferString(config)
 
-	res := post(c, dryrun_url, json_body)
+	res, err := post(ctx, c, dryrun_url, json_body)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if res.Status != "200 OK" && res.Status != "202 Accepted" {
-		return res, nil
+		return res, nil, nil
+	}
+
+	// A planned apply never reached the switch, so there is no result to poll
+	if c.DryRun {
+		return res, map[string]interface{}{"state": "success"}, nil
 	}
 
 	dryrun_url = url + "?dryrun"
 
-	res2, body := get(c, dryrun_url)
+	res2, body, err := get(ctx, c, dryrun_url)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	iterations := 10
 
@@ -249,9 +308,14 @@ func (fc *FullConfig) ApplyConfig(c *Client, config string) (*http.Response, map
 		if body["state"] == "success" || body["state"] == "error" {
 			break
 		}
-		time.Sleep(2 * time.Second)
-		res2, body = get(c, dryrun_url)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return res2, body, err
+		}
+		res2, body, err = get(ctx, c, dryrun_url)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return res2, body
+	return res2, body, nil
 }
@@ -0,0 +1,288 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors []interface{}
+		want   string
+	}{
+		{
+			name: "none",
+			want: "",
+		},
+		{
+			name: "single",
+			errors: []interface{}{
+				map[string]interface{}{"line": float64(3), "message": "Invalid input: vlan 5000"},
+			},
+			want: "line 3 | Invalid input: vlan 5000\n",
+		},
+		{
+			name: "multiple in order",
+			errors: []interface{}{
+				map[string]interface{}{"line": float64(1), "message": "first"},
+				map[string]interface{}{"line": float64(12), "message": "second"},
+			},
+			want: "line 1 | first\nline 12 | second\n",
+		},
+		{
+			name: "malformed entries are reported",
+			errors: []interface{}{
+				map[string]interface{}{"line": float64(2), "message": "valid"},
+				map[string]interface{}{"message": "no line"},
+				map[string]interface{}{"line": "4", "message": "line as a string"},
+				map[string]interface{}{"line": float64(5)},
+				"not an object",
+				nil,
+			},
+			want: "line 2 | valid\n" +
+				`malformed error | {"message":"no line"}` + "\n" +
+				`malformed error | {"line":"4","message":"line as a string"}` + "\n" +
+				`malformed error | {"line":5}` + "\n" +
+				`malformed error | "not an object"` + "\n" +
+				"malformed error | null\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aoscxgo.ConvertErrors(tt.errors); got != tt.want {
+				t.Errorf("convert_errors() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// rejectLines returns a dryrun validator that reports every line containing "invalid".
+func rejectLines(config string) []aoscxtest.ConfigError {
+	var errs []aoscxtest.ConfigError
+	for index, line := range strings.Split(config, "\n") {
+		if strings.Contains(line, "invalid") {
+			errs = append(errs, aoscxtest.ConfigError{Line: index + 1, Message: "Invalid input: " + line})
+		}
+	}
+	return errs
+}
+
+func TestFullConfigCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		noFile     bool
+		wantErr    error
+		wantInErr  string
+		wantConfig string
+	}{
+		{
+			name:       "applied",
+			config:     "hostname core1\nvlan 100\n",
+			wantConfig: "hostname core1\nvlan 100\n",
+		},
+		{
+			name:       "rejected by validation",
+			config:     "hostname core1\ninvalid command\n",
+			wantErr:    aoscxgo.ErrValidation,
+			wantInErr:  "line 2 | Invalid input: invalid command",
+			wantConfig: "hostname original\n",
+		},
+		{
+			name:       "missing file",
+			noFile:     true,
+			wantConfig: "hostname original\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			srv.SetRunningConfig("hostname original\n")
+			srv.SetConfigValidator(rejectLines)
+
+			filename := filepath.Join(t.TempDir(), "config.txt")
+			if !tt.noFile {
+				if err := os.WriteFile(filename, []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fc := aoscxgo.FullConfig{FileName: filename}
+			_, err := fc.Create(sw)
+			if tt.noFile {
+				if err == nil || !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("Create() error = %v, want file not found", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantInErr != "" && !strings.Contains(err.Error(), tt.wantInErr) {
+				t.Errorf("Create() error = %q, want it to contain %q", err, tt.wantInErr)
+			}
+
+			if got := srv.RunningConfig(); got != tt.wantConfig {
+				t.Errorf("running config = %q, want %q", got, tt.wantConfig)
+			}
+			if err == nil && fc.Config != tt.wantConfig {
+				t.Errorf("Config after Create() = %q, want %q", fc.Config, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestFullConfigCreateMissingFileName(t *testing.T) {
+	_, sw := newTestSwitch(t)
+
+	fc := aoscxgo.FullConfig{}
+	if _, err := fc.Create(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Create() without FileName error = %v, want ErrValidation", err)
+	}
+}
+
+func TestFullConfigValidateAndApply(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.SetRunningConfig("hostname original\n")
+	srv.SetConfigValidator(rejectLines)
+
+	fc := aoscxgo.FullConfig{}
+
+	_, body, err := fc.ValidateConfig(sw, "hostname validated\n")
+	if err != nil || body["state"] != "success" {
+		t.Fatalf("ValidateConfig() = %v, %v", body, err)
+	}
+	if got := srv.RunningConfig(); got != "hostname original\n" {
+		t.Errorf("ValidateConfig() changed running config to %q", got)
+	}
+
+	_, body, err = fc.ValidateConfig(sw, "invalid\n")
+	if err != nil || body["state"] != "error" {
+		t.Fatalf("ValidateConfig() invalid = %v, %v", body, err)
+	}
+
+	_, body, err = fc.ApplyConfig(sw, "hostname applied\n")
+	if err != nil || body["state"] != "success" {
+		t.Fatalf("ApplyConfig() = %v, %v", body, err)
+	}
+	if got := srv.RunningConfig(); got != "hostname applied\n" {
+		t.Errorf("running config after ApplyConfig() = %q", got)
+	}
+}
+
+func TestFullConfigPollingCancel(t *testing.T) {
+	type dryrunFunc func(fc *aoscxgo.FullConfig, ctx context.Context, sw *aoscxgo.Client) (*http.Response, map[string]interface{}, error)
+	validate := func(fc *aoscxgo.FullConfig, ctx context.Context, sw *aoscxgo.Client) (*http.Response, map[string]interface{}, error) {
+		return fc.ValidateConfigContext(ctx, sw, "hostname pending\n")
+	}
+	apply := func(fc *aoscxgo.FullConfig, ctx context.Context, sw *aoscxgo.Client) (*http.Response, map[string]interface{}, error) {
+		return fc.ApplyConfigContext(ctx, sw, "hostname pending\n")
+	}
+	withTimeout := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), 100*time.Millisecond)
+	}
+	withCancel := func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		return ctx, cancel
+	}
+
+	tests := []struct {
+		name    string
+		run     dryrunFunc
+		context func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{"validate with deadline", validate, withTimeout, context.DeadlineExceeded},
+		{"validate canceled", validate, withCancel, context.Canceled},
+		{"apply with deadline", apply, withTimeout, context.DeadlineExceeded},
+		{"apply canceled", apply, withCancel, context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			srv.SetDryRunPending(-1)
+
+			ctx, cancel := tt.context()
+			defer cancel()
+			start := time.Now()
+			_, body, err := tt.run(&aoscxgo.FullConfig{}, ctx, sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned after %v, want the poll to stop when ctx is done", elapsed)
+			}
+			if body["state"] != "pending" {
+				t.Errorf("body = %v, want the last pending result", body)
+			}
+		})
+	}
+}
+
+func TestFullConfigPollingPending(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.SetDryRunPending(1)
+
+	fc := aoscxgo.FullConfig{}
+	_, body, err := fc.ValidateConfig(sw, "hostname pending\n")
+	if err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+	if body["state"] != "success" {
+		t.Errorf("ValidateConfig() state = %v, want success after the pending poll", body["state"])
+	}
+
+	polls := 0
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodGet && req.Query == "dryrun" {
+			polls++
+		}
+	}
+	if polls != 2 {
+		t.Errorf("dryrun polled %d times, want 2", polls)
+	}
+}
+
+func TestFullConfigGetAndDownload(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	srv.SetRunningConfig("hostname core1\ninterface 1/1/1\n    no shutdown\n")
+
+	fc := aoscxgo.FullConfig{}
+	if err := fc.Get(sw); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if fc.Config != srv.RunningConfig() {
+		t.Errorf("Config = %q, want %q", fc.Config, srv.RunningConfig())
+	}
+
+	if diff := fc.CompareConfig(srv.RunningConfig()); diff != "" {
+		t.Errorf("CompareConfig() with same config = %q, want empty", diff)
+	}
+	if diff := fc.CompareConfig("hostname core2\n"); diff == "" {
+		t.Error("CompareConfig() with different config is empty")
+	}
+
+	filename := filepath.Join(t.TempDir(), "running.txt")
+	if err := fc.DownloadConfig(sw, filename); err != nil {
+		t.Fatalf("DownloadConfig() error = %v", err)
+	}
+	downloaded, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(downloaded) != srv.RunningConfig() {
+		t.Errorf("downloaded config = %q, want %q", downloaded, srv.RunningConfig())
+	}
+}
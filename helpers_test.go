@@ -0,0 +1,73 @@
+package aoscxgo_test
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// newTestSwitch starts a fake switch and returns it with a connected client.
+func newTestSwitch(t *testing.T) (*aoscxtest.Server, *aoscxgo.Client) {
+	t.Helper()
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	sw, err := aoscxgo.Connect(srv.Client())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return srv, sw
+}
+
+// createVlans creates the given VLANs on the fake switch.
+func createVlans(t *testing.T, sw *aoscxgo.Client, ids ...int) {
+	t.Helper()
+	for _, id := range ids {
+		vlan := aoscxgo.Vlan{VlanId: id, Name: "VLAN" + strconv.Itoa(id)}
+		if err := vlan.Create(sw); err != nil {
+			t.Fatalf("create VLAN %d: %v", id, err)
+		}
+	}
+}
+
+// referenceKeys returns the sorted keys of a stored reference attribute such as vlan_trunks.
+func referenceKeys(value interface{}) []string {
+	references, _ := value.(map[string]interface{})
+	keys := make([]string, 0, len(references))
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedInts returns a sorted copy of a VlanIds slice.
+func sortedInts(values []int) []int {
+	ints := append([]int{}, values...)
+	sort.Ints(ints)
+	return ints
+}
+
+// sortedStrings returns a sorted copy of an Ipv4 or Ipv6 slice.
+func sortedStrings(values []string) []string {
+	strs := append([]string{}, values...)
+	sort.Strings(strs)
+	return strs
+}
+
+// replacer is implemented by the resources that can be updated with PATCH or PUT.
+type replacer interface {
+	Update(c *aoscxgo.Client) error
+	Replace(c *aoscxgo.Client) error
+}
+
+// update changes resource with Replace when use_put is set, Update otherwise.
+func update(sw *aoscxgo.Client, resource replacer, use_put bool) error {
+	if use_put {
+		return resource.Replace(sw)
+	}
+	return resource.Update(sw)
+}
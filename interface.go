@@ -2,8 +2,9 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 )
@@ -11,12 +12,13 @@ import (
 type Interface struct {
 
 	// Connection properties.
-	Name             string                 `json:"name"`
-	Description      string                 `json:"description"`
-	AdminState       string                 `json:"admin"`
-	InterfaceDetails map[string]interface{} `json:"details"`
-	materialized     bool                   `json:"materialized"`
-	uri              string                 `json:"uri"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	AdminState       string              `json:"admin"`
+	InterfaceDetails InterfaceAttributes `json:"details"`
+	Mask             FieldMask           `json:"-"`
+	materialized     bool
+	uri              string
 }
 
 // checkName validates if interface Name is valid or not
@@ -36,7 +38,7 @@ func (i *Interface) checkValues() error {
 	if !checkName(i.Name) {
 		return &RequestError{
 			StatusCode: "Invalid Required Value: Name",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -45,7 +47,7 @@ func (i *Interface) checkValues() error {
 	if i.AdminState != "down" && i.AdminState != "up" {
 		return &RequestError{
 			StatusCode: status_str,
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 	return nil
@@ -53,8 +55,18 @@ func (i *Interface) checkValues() error {
 
 // Create performs POST to create Interface configuration on the given Client object.
 func (i *Interface) Create(c *Client) error {
+	return i.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (i *Interface) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
-	url_str := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
+	url_str := c.restURL() + "/" + base_uri
 
 	int_str := url.PathEscape(i.Name)
 
@@ -84,13 +96,13 @@ func (i *Interface) Create(c *Client) error {
 
 	json_body := bytes.NewBuffer(postBody)
 
-	res := post(c, url_str, json_body)
+	res, err := post(ctx, c, url_str, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "201 Created" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Create Error"),
-		}
+		return newAPIError("Create Error", res)
 	}
 
 	i.materialized = true
@@ -99,7 +111,18 @@ func (i *Interface) Create(c *Client) error {
 }
 
 // Update performs PATCH to update Interface configuration on the given Client object.
+// Only the description and admin state are sent, when they differ from the switch.
 func (i *Interface) Update(c *Client) error {
+	return i.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (i *Interface) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	err := i.checkValues()
 	if err != nil {
@@ -109,7 +132,15 @@ func (i *Interface) Update(c *Client) error {
 
 	int_str := url.PathEscape(i.Name)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
+
+	changed, err := changes(ctx, c, i)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
 
 	patchMap := map[string]interface{}{
 		"description": i.Description,
@@ -124,18 +155,19 @@ func (i *Interface) Update(c *Client) error {
 		patchMap["user_config"] = map[string]interface{}{
 			"admin": "up"}
 	}
+	patchMap = changedBody(patchMap, changed)
 
 	patchBody, _ := json.Marshal(patchMap)
 
 	json_body := bytes.NewBuffer(patchBody)
 
-	res := patch(c, url, json_body)
+	res, err := patch(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Update Error"),
-		}
+		return newAPIError("Update Error", res)
 	}
 
 	return nil
@@ -143,6 +175,16 @@ func (i *Interface) Update(c *Client) error {
 
 // Delete performs PUT to remove/default Interface configuration from the given Client object.
 func (i *Interface) Delete(c *Client) error {
+	return i.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (i *Interface) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	int_str := url.PathEscape(i.Name)
 
@@ -152,18 +194,18 @@ func (i *Interface) Delete(c *Client) error {
 
 	json_body := bytes.NewBuffer(putBody)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
-	//res := delete(c,  url)
+	url := c.restURL() + "/" + base_uri + "/" + int_str
+	//res := delete(ctx, c,  url)
 
 	//need logic for handling interfaces between platforms
 
-	res := put(c, url, json_body)
+	res, err := put(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" && res.Status != "200 OK" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Delete Error"),
-		}
+		return newAPIError("Delete Error", res)
 	}
 
 	return nil
@@ -171,43 +213,119 @@ func (i *Interface) Delete(c *Client) error {
 
 // Get performs GET to retrieve Interface configuration from the given Client object.
 func (i *Interface) Get(c *Client) error {
+	return i.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (i *Interface) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	int_str := url.PathEscape(i.Name)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + ""
+	url := c.restURL() + "/" + base_uri + "/" + int_str + ""
 
-	res, body := get(c, url)
-
-	if res.Status != "200 OK" {
+	var attributes InterfaceAttributes
+	if err := getJSON(ctx, c, "Retrieval Error", url, &attributes); err != nil {
 		i.materialized = false
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Retrieval Error"),
-		}
-	}
-
-	if i.InterfaceDetails == nil {
-		i.InterfaceDetails = map[string]interface{}{}
+		return err
 	}
 
-	for key, value := range body {
-		i.InterfaceDetails[key] = value
-		if key == "description" && value != nil {
-			i.Description = value.(string)
-		}
+	i.loadDetails(attributes)
 
-		if key == "admin" && value != nil {
-			i.AdminState = value.(string)
-		}
+	return nil
+}
 
+// loadDetails populates the Interface from the attributes returned by the switch.
+func (i *Interface) loadDetails(attributes InterfaceAttributes) {
+	i.InterfaceDetails = attributes
+	if attributes.Has("description") {
+		i.Description = attributes.Description
+	}
+	if attributes.Has("admin") {
+		i.AdminState = attributes.Admin
 	}
 
 	i.materialized = true
-
-	return nil
 }
 
 // GetStatus returns True if Interface exists on Client object or False if not.
 func (i *Interface) GetStatus() bool {
 	return i.materialized
 }
+
+// Kind returns "Interface".
+func (i *Interface) Kind() string {
+	return "Interface"
+}
+
+// Key returns the name of the Interface.
+func (i *Interface) Key() string {
+	return i.Name
+}
+
+// URI returns the path of the Interface relative to the versioned REST API.
+func (i *Interface) URI() string {
+	return interfaceURI(i.Name)
+}
+
+// Exists reports whether the Interface is present on the switch.
+func (i *Interface) Exists(c *Client) (bool, error) {
+	return i.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (i *Interface) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, i, CapabilityInterfaces)
+}
+
+// interfaceURI returns the path of the named interface relative to the
+// versioned REST API, e.g. "system/interfaces/1%2F1%2F1".
+func interfaceURI(name string) string {
+	return "system/interfaces/" + url.PathEscape(name)
+}
+
+// Ensure creates the Interface, or updates its Description and AdminState when they differ from the switch.
+func (i *Interface) Ensure(c *Client) (EnsureResult, error) {
+	return i.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (i *Interface) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, i)
+}
+
+// EnsureAbsent resets the Interface with Delete unless it has no configuration left.
+func (i *Interface) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return i.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (i *Interface) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureDefault(ctx, c, i)
+}
+
+// state returns the attributes of the Interface, see stateful.
+func (i *Interface) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, i.Mask)
+	state.add("description", i.Description, i.Description != "")
+	state.add("admin", i.AdminState, i.AdminState != "")
+	return state.values
+}
+
+// writableState reads the writable attributes of the Interface from the switch.
+func (i *Interface) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityWritableSelector); err != nil {
+		return nil, err
+	}
+
+	var attributes InterfaceAttributes
+	if err := c.Get(ctx, i.URI(), &QueryOptions{Selector: SelectorWritable}, &attributes); err != nil {
+		return nil, err
+	}
+	current := Interface{Name: i.Name}
+	current.loadDetails(attributes)
+	return current.state(false), nil
+}
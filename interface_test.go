@@ -0,0 +1,147 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"1/1/1", true},
+		{"1/1/48", true},
+		{"2/1/10", true},
+		{"lag1", false},
+		{"vlan100", false},
+		{"1/1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aoscxgo.CheckName(tt.name); got != tt.want {
+				t.Errorf("checkName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterfaceCheckValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		iface   aoscxgo.Interface
+		wantErr bool
+	}{
+		{name: "up", iface: aoscxgo.Interface{Name: "1/1/1", AdminState: "up"}},
+		{name: "down", iface: aoscxgo.Interface{Name: "1/1/1", AdminState: "down"}},
+		{name: "invalid name", iface: aoscxgo.Interface{Name: "eth0", AdminState: "up"}, wantErr: true},
+		{name: "missing admin state", iface: aoscxgo.Interface{Name: "1/1/1"}, wantErr: true},
+		{name: "invalid admin state", iface: aoscxgo.Interface{Name: "1/1/1", AdminState: "enabled"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.iface.CheckValues()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, aoscxgo.ErrValidation) {
+				t.Errorf("checkValues() error = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestInterfaceCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		iface   aoscxgo.Interface
+		wantErr error
+	}{
+		{
+			name:  "new interface",
+			iface: aoscxgo.Interface{Name: "1/1/20", Description: "spare", AdminState: "up"},
+		},
+		{
+			name:    "existing interface",
+			iface:   aoscxgo.Interface{Name: "1/1/1", AdminState: "up"},
+			wantErr: aoscxgo.ErrConflict,
+		},
+		{
+			name:    "invalid admin state",
+			iface:   aoscxgo.Interface{Name: "1/1/20", AdminState: "on"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+
+			err := tt.iface.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !tt.iface.GetStatus() {
+				t.Error("GetStatus() = false after Create")
+			}
+			stored, ok := srv.Interface(tt.iface.Name)
+			if !ok {
+				t.Fatal("interface not stored on switch")
+			}
+			user_config, _ := stored["user_config"].(map[string]interface{})
+			if stored["admin"] != tt.iface.AdminState || user_config["admin"] != tt.iface.AdminState {
+				t.Errorf("stored interface = %v", stored)
+			}
+		})
+	}
+}
+
+func TestInterfaceGetUpdateDelete(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+
+	update := aoscxgo.Interface{Name: "1/1/2", Description: "server", AdminState: "up"}
+	if err := update.Update(sw); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got := aoscxgo.Interface{Name: "1/1/2"}
+	if err := got.Get(sw); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Description != "server" || got.AdminState != "up" || !got.GetStatus() {
+		t.Errorf("Get() = %+v", got)
+	}
+	if got.InterfaceDetails.Name != "1/1/2" {
+		t.Errorf("InterfaceDetails.Name = %v", got.InterfaceDetails.Name)
+	}
+
+	if err := got.Delete(sw); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	stored, _ := srv.Interface("1/1/2")
+	if _, ok := stored["description"]; ok {
+		t.Errorf("interface not defaulted by Delete(): %v", stored)
+	}
+
+	missing := aoscxgo.Interface{Name: "1/1/99"}
+	if err := missing.Get(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Get() missing interface error = %v, want ErrNotFound", err)
+	}
+	if missing.GetStatus() {
+		t.Error("GetStatus() = true for missing interface")
+	}
+
+	invalid := aoscxgo.Interface{Name: "1/1/2", AdminState: "enabled"}
+	if err := invalid.Update(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Update() invalid admin state error = %v, want ErrValidation", err)
+	}
+}
@@ -2,8 +2,9 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 )
@@ -11,19 +12,30 @@ import (
 type L2Interface struct {
 
 	// Connection properties.
-	Interface        Interface              `json:"interface"`
-	Description      string                 `json:"description"`
-	VlanMode         string                 `json:"vlan_mode"`
-	VlanIds          []interface{}          `json:"vlan_ids"`
-	VlanTag          int                    `json:"vlan_tag"`
-	TrunkAllowedAll  bool                   `json:"trunk_allowed_all"`
-	NativeVlanTag    bool                   `json:"native_vlan_tag"`
-	InterfaceDetails map[string]interface{} `json:"details"`
-	materialized     bool                   `json:"materialized"`
+	Interface        Interface           `json:"interface"`
+	Description      string              `json:"description"`
+	VlanMode         string              `json:"vlan_mode"`
+	VlanIds          []int               `json:"vlan_ids"`
+	VlanTag          int                 `json:"vlan_tag"`
+	TrunkAllowedAll  bool                `json:"trunk_allowed_all"`
+	NativeVlanTag    bool                `json:"native_vlan_tag"`
+	InterfaceDetails InterfaceAttributes `json:"details"`
+	Mask             FieldMask           `json:"-"`
+	materialized     bool
 }
 
 // Create performs PATCH to update L2Interface configuration on the given Client object.
 func (i *L2Interface) Create(c *Client) error {
+	return i.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (i *L2Interface) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 
 	patchMap := map[string]interface{}{}
@@ -31,7 +43,7 @@ func (i *L2Interface) Create(c *Client) error {
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to configure L2Interface",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -42,7 +54,7 @@ func (i *L2Interface) Create(c *Client) error {
 	}
 
 	int_str := url.PathEscape(i.Interface.Name)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
 	if i.VlanMode == "access" || i.VlanMode == "" {
 		if i.VlanTag == 0 {
@@ -53,14 +65,14 @@ func (i *L2Interface) Create(c *Client) error {
 			VlanId: i.VlanTag,
 		}
 
-		err := tmp_vlan.Get(c)
+		err := tmp_vlan.GetContext(ctx, c)
 
 		if err != nil && !tmp_vlan.materialized {
-			err = tmp_vlan.Create(c)
+			err = tmp_vlan.CreateContext(ctx, c)
 			if err != nil && !tmp_vlan.materialized {
 				return &RequestError{
 					StatusCode: "Vlan Not found unable to configure L2Interface",
-					Err:        errors.New("Create Error"),
+					Err:        fmt.Errorf("Create Error: %w", ErrDependency),
 				}
 			}
 		}
@@ -69,25 +81,22 @@ func (i *L2Interface) Create(c *Client) error {
 
 	} else if i.VlanMode == "trunk" || i.VlanMode == "native-untagged" || i.VlanMode == "native-tagged" {
 
-		if i.NativeVlanTag {
-			i.VlanMode = "native-tagged"
-		} else {
-			i.VlanMode = "native-untagged"
-		}
+		i.VlanMode = trunkVlanMode(i.VlanMode, i.NativeVlanTag)
+		i.NativeVlanTag = i.VlanMode == "native-tagged"
 
-		if i.VlanTag == 1 {
+		if i.VlanTag == 1 || i.VlanTag == 0 {
 			patchMap["vlan_tag"] = nil
 		} else {
 			tmp_vlan := Vlan{
 				VlanId: i.VlanTag,
 			}
 
-			err := tmp_vlan.Get(c)
+			err := tmp_vlan.GetContext(ctx, c)
 
 			if err != nil && !tmp_vlan.materialized {
 				return &RequestError{
 					StatusCode: "Vlan Not found unable to configure L2Interface",
-					Err:        errors.New("Create Error"),
+					Err:        fmt.Errorf("Create Error: %w", ErrDependency),
 				}
 			}
 			patchMap["vlan_tag"] = map[string]interface{}{
@@ -100,8 +109,8 @@ func (i *L2Interface) Create(c *Client) error {
 		if !i.TrunkAllowedAll {
 			// Test what is behavior of List being empty or not
 			for _, item := range i.VlanIds {
-				tmp_vlan_obj := Vlan{VlanId: item.(int)}
-				err = tmp_vlan_obj.Get(c)
+				tmp_vlan_obj := Vlan{VlanId: item}
+				err = tmp_vlan_obj.GetContext(ctx, c)
 				if err == nil {
 					vlan_trunks[strconv.Itoa(tmp_vlan_obj.VlanId)] = tmp_vlan_obj.GetURI()
 				}
@@ -117,7 +126,7 @@ func (i *L2Interface) Create(c *Client) error {
 		status_str := "Invalid Required Value: VlanMode - valid options are 'access' or 'trunk' received: " + i.VlanMode
 		return &RequestError{
 			StatusCode: status_str,
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -126,13 +135,13 @@ func (i *L2Interface) Create(c *Client) error {
 		Name: i.Interface.Name,
 	}
 
-	err = tmp_int.Get(c)
+	err = tmp_int.GetContext(ctx, c)
 
 	if err != nil {
 		return err
 
 	} else if !tmp_int.materialized {
-		err = i.Interface.Create(c)
+		err = i.Interface.CreateContext(ctx, c)
 
 		if err != nil {
 			return err
@@ -156,13 +165,13 @@ func (i *L2Interface) Create(c *Client) error {
 
 	json_body := bytes.NewBuffer(patchBody)
 
-	res := patch(c, url, json_body)
+	res, err := patch(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Create Error"),
-		}
+		return newAPIError("Create Error", res)
 	}
 
 	i.materialized = true
@@ -170,20 +179,61 @@ func (i *L2Interface) Create(c *Client) error {
 	return nil
 }
 
-// Update performs PATCH or PUT to update L2Interface configuration on the given Client object.
-func (i *L2Interface) Update(c *Client, use_put bool) error {
+// trunkVlanMode returns the AOS-CX vlan_mode for a trunk port or LAG. An explicit
+// native-tagged or native-untagged vlan_mode is kept, "trunk" is mapped using
+// native_vlan_tag.
+func trunkVlanMode(vlan_mode string, native_vlan_tag bool) string {
+	if vlan_mode == "native-tagged" || vlan_mode == "native-untagged" {
+		return vlan_mode
+	}
+	if native_vlan_tag {
+		return "native-tagged"
+	}
+	return "native-untagged"
+}
+
+// Update performs PATCH to update L2Interface configuration on the given Client object.
+// Only the attributes that differ from the writable state of the port are sent.
+func (i *L2Interface) Update(c *Client) error {
+	return i.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (i *L2Interface) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", i.Kind(), i.Key())
+	return i.update(ctx, c, false)
+}
+
+// Replace performs PUT to replace L2Interface configuration on the given Client object.
+// Writable attributes not managed by the L2Interface are read first and sent back unchanged.
+func (i *L2Interface) Replace(c *Client) error {
+	return i.ReplaceContext(context.Background(), c)
+}
+
+// ReplaceContext is like Replace but uses ctx for every request made to the switch.
+func (i *L2Interface) ReplaceContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "replace", i.Kind(), i.Key())
+	return i.update(ctx, c, true)
+}
+
+// update sends the configuration with PATCH, or with PUT when use_put is set.
+func (i *L2Interface) update(ctx context.Context, c *Client, use_put bool) error {
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 
 	updateMap := map[string]interface{}{}
 
 	if use_put {
 		tmp_l2_int := L2Interface{Interface: i.Interface}
-		err := tmp_l2_int.Get(c)
+		err := tmp_l2_int.GetContext(ctx, c)
 		if err != nil {
 			return err
 		}
 
-		for key, value := range tmp_l2_int.InterfaceDetails {
+		for key, value := range tmp_l2_int.InterfaceDetails.values() {
 			updateMap[key] = value
 		}
 	}
@@ -191,7 +241,7 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to configure L2Interface",
-			Err:        errors.New("Update Error"),
+			Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 		}
 	}
 
@@ -201,11 +251,26 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 
 	}
 
+	// A PATCH only carries the attributes that differ from the switch
+	var changed []Difference
+	if !use_put {
+		changed, err = changes(ctx, c, i)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			i.materialized = true
+			return nil
+		}
+	}
+
 	int_str := url.PathEscape(i.Interface.Name)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
-	if i.VlanMode == "access" || i.VlanMode == "" {
+	if !use_put && !hasDifference(changed, "vlan_mode", "vlan_tag", "vlan_trunks") {
+		// The VLAN configuration is left as it is
+	} else if i.VlanMode == "access" || i.VlanMode == "" {
 		if i.VlanTag == 0 {
 			i.VlanTag = 1
 		}
@@ -214,13 +279,13 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 			VlanId: i.VlanTag,
 		}
 
-		err := tmp_vlan.Get(c)
+		err := tmp_vlan.GetContext(ctx, c)
 
 		if err != nil && !tmp_vlan.materialized {
-			err_str := "Vlan Not found unable to configure L2Interface Stats " + err.(*RequestError).StatusCode + " | " + strconv.FormatBool(tmp_vlan.materialized)
+			err_str := "Vlan Not found unable to configure L2Interface Stats " + err.Error() + " | " + strconv.FormatBool(tmp_vlan.materialized)
 			return &RequestError{
 				StatusCode: err_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", ErrDependency),
 			}
 		}
 		updateMap["vlan_tag"] = map[string]interface{}{strconv.Itoa(i.VlanTag): tmp_vlan.GetURI()}
@@ -228,11 +293,8 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 
 	} else if i.VlanMode == "trunk" || i.VlanMode == "native-untagged" || i.VlanMode == "native-tagged" {
 
-		if i.NativeVlanTag {
-			i.VlanMode = "native-tagged"
-		} else {
-			i.VlanMode = "native-untagged"
-		}
+		i.VlanMode = trunkVlanMode(i.VlanMode, i.NativeVlanTag)
+		i.NativeVlanTag = i.VlanMode == "native-tagged"
 
 		if i.VlanTag == 1 || i.VlanTag == 0 {
 			updateMap["vlan_tag"] = nil
@@ -241,13 +303,13 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 				VlanId: i.VlanTag,
 			}
 
-			err := tmp_vlan.Get(c)
+			err := tmp_vlan.GetContext(ctx, c)
 
 			if err != nil && !tmp_vlan.materialized {
-				err_str := "Vlan Not found unable to configure L2Interface Stats " + err.(*RequestError).StatusCode + " | " + strconv.Itoa(i.VlanTag)
+				err_str := "Vlan Not found unable to configure L2Interface Stats " + err.Error() + " | " + strconv.Itoa(i.VlanTag)
 				return &RequestError{
 					StatusCode: err_str,
-					Err:        errors.New("Update Error"),
+					Err:        fmt.Errorf("Update Error: %w", ErrDependency),
 				}
 			}
 			updateMap["vlan_tag"] = map[string]interface{}{
@@ -260,8 +322,8 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 		if !i.TrunkAllowedAll {
 			// Test what is behavior of List being empty or not
 			for _, item := range i.VlanIds {
-				tmp_vlan_obj := Vlan{VlanId: item.(int)}
-				err = tmp_vlan_obj.Get(c)
+				tmp_vlan_obj := Vlan{VlanId: item}
+				err = tmp_vlan_obj.GetContext(ctx, c)
 				if err == nil {
 					vlan_trunks[strconv.Itoa(tmp_vlan_obj.VlanId)] = tmp_vlan_obj.GetURI()
 				}
@@ -277,7 +339,7 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 		status_str := "Invalid Required Value: VlanMode - valid options are 'access' or 'trunk' received: " + i.VlanMode
 		return &RequestError{
 			StatusCode: status_str,
-			Err:        errors.New("Update Error"),
+			Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 		}
 	}
 
@@ -294,30 +356,30 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 			"admin": "up"}
 	}
 
+	if !use_put {
+		updateMap = changedBody(updateMap, changed)
+	}
+
 	updateBody, _ := json.Marshal(updateMap)
 
 	json_body := bytes.NewBuffer(updateBody)
 
 	if use_put {
-		res := put(c, url, json_body)
+		res, err := put(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.Status != "200 OK" {
-			status_str := string(updateBody) + "PUT status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 
 	} else {
-		res := patch(c, url, json_body)
+		res, err := patch(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.Status != "204 No Content" {
-			status_str := string(updateBody) + "PATCH status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 	}
 
@@ -328,11 +390,21 @@ func (i *L2Interface) Update(c *Client, use_put bool) error {
 
 // Delete performs PUT to remove/default L2Interface configuration from the given Client object.
 func (i *L2Interface) Delete(c *Client) error {
+	return i.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (i *L2Interface) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to delete L2Interface",
-			Err:        errors.New("Delete Error"),
+			Err:        fmt.Errorf("Delete Error: %w", ErrValidation),
 		}
 	}
 	int_str := url.PathEscape(i.Interface.Name)
@@ -343,17 +415,17 @@ func (i *L2Interface) Delete(c *Client) error {
 
 	json_body := bytes.NewBuffer(putBody)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
 	//need logic for handling interfaces between platforms
 
-	res := put(c, url, json_body)
+	res, err := put(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" && res.Status != "200 OK" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Delete Error"),
-		}
+		return newAPIError("Delete Error", res)
 	}
 
 	return nil
@@ -361,83 +433,57 @@ func (i *L2Interface) Delete(c *Client) error {
 
 // Get performs GET to retrieve L2Interface configuration from the given Client object.
 func (i *L2Interface) Get(c *Client) error {
+	return i.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (i *L2Interface) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityWritableSelector); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to retrieve L2Interface",
-			Err:        errors.New("Get Error"),
+			Err:        fmt.Errorf("Get Error: %w", ErrValidation),
 		}
 	}
 	int_str := url.PathEscape(i.Interface.Name)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "?selector=writable"
+	url := c.restURL() + "/" + base_uri + "/" + int_str + "?selector=writable"
 
-	res, body := get(c, url)
-
-	if res.Status != "200 OK" {
+	var attributes InterfaceAttributes
+	if err := getJSON(ctx, c, "Get Error", url, &attributes); err != nil {
 		i.materialized = false
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Get Error"),
-		}
+		return err
 	}
 
-	if i.Interface.InterfaceDetails == nil {
-		i.Interface.InterfaceDetails = map[string]interface{}{}
+	i.InterfaceDetails = attributes
+	i.Interface.InterfaceDetails = attributes
+	if attributes.Has("description") {
+		i.Description = attributes.Description
+		i.Interface.Description = attributes.Description
 	}
 
-	for key, value := range body {
-		i.Interface.InterfaceDetails[key] = value
-		if key == "description" && value != nil {
-			i.Description = value.(string)
-			i.Interface.Description = value.(string)
-		}
-
-		if key == "vlan_mode" && value != nil {
-			i.VlanMode = value.(string)
-			if i.VlanMode == "native-tagged" {
-				i.NativeVlanTag = true
-			} else {
-				i.NativeVlanTag = false
-			}
-		}
-
-		if key == "admin" && value != nil {
-			i.Interface.AdminState = value.(string)
-		}
-
-		if key == "vlan_tag" && value != nil {
-			// convert json to value singular
-			// "vlan_tag": {
-			// 	"42": "/rest/v10.09/system/vlans/42"
-			//   },
-			for key, _ := range value.(map[string]interface{}) {
-				vlan_int, _ := strconv.Atoi(key)
-				i.VlanTag = vlan_int
-			}
-
-		}
-
-		if key == "vlan_trunks" && len(value.(map[string]interface{})) != 0 {
-			// convert json to value singular
-			// "vlan_trunks": {
-			// 	"42": "/rest/v10.09/system/vlans/42"
-			//   },
-			tmp_splice := []interface{}{}
-			for key, _ := range value.(map[string]interface{}) {
-				vlan_int, _ := strconv.Atoi(key)
-				tmp_splice = append(tmp_splice, vlan_int)
-
-			}
+	if attributes.Has("vlan_mode") {
+		i.VlanMode = attributes.VlanMode
+		i.NativeVlanTag = i.VlanMode == "native-tagged"
+	}
 
-			i.VlanIds = tmp_splice
-			i.TrunkAllowedAll = false
+	if attributes.Has("admin") {
+		i.Interface.AdminState = attributes.Admin
+	}
 
-		} else if key == "vlan_trunks" && len(value.(map[string]interface{})) == 0 {
-			i.TrunkAllowedAll = true
-			i.VlanIds = []interface{}{}
-		}
+	// vlan_tag and vlan_trunks reference the VLANs by id, e.g.
+	// "vlan_tag": {"42": "/rest/v10.09/system/vlans/42"}
+	for _, vlan_int := range attributes.VlanTag.IntKeys() {
+		i.VlanTag = vlan_int
+	}
 
+	if attributes.Has("vlan_trunks") {
+		i.VlanIds = attributes.VlanTrunks.IntKeys()
+		i.TrunkAllowedAll = len(i.VlanIds) == 0
 	}
 
 	i.materialized = true
@@ -449,3 +495,76 @@ func (i *L2Interface) Get(c *Client) error {
 func (i *L2Interface) GetStatus() bool {
 	return i.materialized
 }
+
+// Kind returns "L2Interface".
+func (i *L2Interface) Kind() string {
+	return "L2Interface"
+}
+
+// Key returns the name of the underlying Interface.
+func (i *L2Interface) Key() string {
+	return i.Interface.Name
+}
+
+// URI returns the path of the L2Interface relative to the versioned REST API.
+func (i *L2Interface) URI() string {
+	return interfaceURI(i.Interface.Name)
+}
+
+// Exists reports whether its interface is present on the switch.
+func (i *L2Interface) Exists(c *Client) (bool, error) {
+	return i.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (i *L2Interface) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, i, CapabilityInterfaces)
+}
+
+// Ensure configures the L2Interface when its description, admin state or VLANs differ from the switch.
+func (i *L2Interface) Ensure(c *Client) (EnsureResult, error) {
+	return i.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (i *L2Interface) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, i)
+}
+
+// EnsureAbsent resets the L2Interface with Delete unless its port has no configuration left.
+func (i *L2Interface) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return i.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (i *L2Interface) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureDefault(ctx, c, i)
+}
+
+// state returns the attributes of the L2Interface, see stateful. The VLAN
+// configuration is managed once any of its fields is set.
+func (i *L2Interface) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, i.Mask)
+	state.add("description", i.Description, i.Description != "")
+	state.add("admin", i.Interface.AdminState, i.Interface.AdminState != "")
+	state.add("routing", !desired && i.InterfaceDetails.Routing, true)
+
+	vlan_mode := i.VlanMode
+	if desired && (vlan_mode == "" || vlan_mode == "access") {
+		vlan_mode = "access"
+	} else if desired {
+		vlan_mode = trunkVlanMode(i.VlanMode, i.NativeVlanTag)
+	}
+	set := i.VlanMode != "" || i.VlanTag != 0 || len(i.VlanIds) > 0 || i.TrunkAllowedAll
+	state.addVlanState(vlan_mode, i.VlanTag, i.VlanIds, i.TrunkAllowedAll, set)
+	return state.values
+}
+
+// writableState reads the writable attributes of the port from the switch.
+func (i *L2Interface) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	current := L2Interface{Interface: Interface{Name: i.Interface.Name}}
+	if err := current.GetContext(ctx, c); err != nil {
+		return nil, err
+	}
+	return current.state(false), nil
+}
@@ -0,0 +1,258 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestL2InterfaceCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		l2         aoscxgo.L2Interface
+		wantErr    error
+		wantMode   string
+		wantTag    []string
+		wantTrunks []string
+	}{
+		{
+			name:     "access defaults to VLAN 1",
+			l2:       aoscxgo.L2Interface{VlanMode: "access"},
+			wantMode: "access",
+			wantTag:  []string{"1"},
+		},
+		{
+			name:     "empty mode is access",
+			l2:       aoscxgo.L2Interface{VlanTag: 100},
+			wantMode: "access",
+			wantTag:  []string{"100"},
+		},
+		{
+			name:    "access on missing VLAN",
+			l2:      aoscxgo.L2Interface{VlanMode: "access", VlanTag: 999},
+			wantErr: aoscxgo.ErrDependency,
+		},
+		{
+			name:       "trunk maps to native-untagged",
+			l2:         aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 100, VlanIds: []int{100, 200}},
+			wantMode:   "native-untagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"100", "200"},
+		},
+		{
+			name:       "trunk with NativeVlanTag maps to native-tagged",
+			l2:         aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 100, NativeVlanTag: true, VlanIds: []int{200}},
+			wantMode:   "native-tagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"200"},
+		},
+		{
+			name:       "explicit native-tagged is kept",
+			l2:         aoscxgo.L2Interface{VlanMode: "native-tagged", VlanTag: 100, VlanIds: []int{200}},
+			wantMode:   "native-tagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"200"},
+		},
+		{
+			name:       "explicit native-untagged is kept",
+			l2:         aoscxgo.L2Interface{VlanMode: "native-untagged", VlanTag: 100, NativeVlanTag: true, VlanIds: []int{200}},
+			wantMode:   "native-untagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"200"},
+		},
+		{
+			name:       "trunk without native VLAN",
+			l2:         aoscxgo.L2Interface{VlanMode: "trunk", VlanIds: []int{100}},
+			wantMode:   "native-untagged",
+			wantTrunks: []string{"100"},
+		},
+		{
+			name:       "trunk allowing all VLANs",
+			l2:         aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 1, TrunkAllowedAll: true, VlanIds: []int{100}},
+			wantMode:   "native-untagged",
+			wantTrunks: []string{},
+		},
+		{
+			name:       "trunk skips missing VLANs",
+			l2:         aoscxgo.L2Interface{VlanMode: "trunk", VlanIds: []int{100, 999}},
+			wantMode:   "native-untagged",
+			wantTrunks: []string{"100"},
+		},
+		{
+			name:    "trunk on missing native VLAN",
+			l2:      aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 999},
+			wantErr: aoscxgo.ErrDependency,
+		},
+		{
+			name:    "invalid mode",
+			l2:      aoscxgo.L2Interface{VlanMode: "hybrid"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 100, 200)
+
+			tt.l2.Interface = aoscxgo.Interface{Name: "1/1/1", AdminState: "up"}
+			tt.l2.Description = "access port"
+
+			err := tt.l2.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored, _ := srv.Interface("1/1/1")
+			if stored["vlan_mode"] != tt.wantMode {
+				t.Errorf("vlan_mode = %v, want %q", stored["vlan_mode"], tt.wantMode)
+			}
+			if got := referenceKeys(stored["vlan_tag"]); len(got) != len(tt.wantTag) || (len(got) > 0 && !reflect.DeepEqual(got, tt.wantTag)) {
+				t.Errorf("vlan_tag = %v, want %v", stored["vlan_tag"], tt.wantTag)
+			}
+			if tt.wantTrunks != nil && !reflect.DeepEqual(referenceKeys(stored["vlan_trunks"]), tt.wantTrunks) {
+				t.Errorf("vlan_trunks = %v, want %v", stored["vlan_trunks"], tt.wantTrunks)
+			}
+			if stored["routing"] != false || stored["description"] != "access port" {
+				t.Errorf("stored interface = %v", stored)
+			}
+			if tt.l2.NativeVlanTag != (tt.wantMode == "native-tagged") {
+				t.Errorf("NativeVlanTag = %v after Create with mode %q", tt.l2.NativeVlanTag, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestL2InterfaceCreateValidation(t *testing.T) {
+	_, sw := newTestSwitch(t)
+
+	tests := []struct {
+		name string
+		l2   aoscxgo.L2Interface
+	}{
+		{name: "missing interface", l2: aoscxgo.L2Interface{VlanMode: "access"}},
+		{name: "invalid interface name", l2: aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "eth0", AdminState: "up"}}},
+		{name: "invalid admin state", l2: aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1", AdminState: "on"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.l2.Create(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+				t.Errorf("Create() error = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestL2InterfaceTransitions(t *testing.T) {
+	access := aoscxgo.L2Interface{VlanMode: "access", VlanTag: 100}
+	trunk := aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 100, VlanIds: []int{100, 200}}
+	tagged := aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 200, NativeVlanTag: true, VlanIds: []int{200}}
+	all := aoscxgo.L2Interface{VlanMode: "trunk", VlanTag: 100, TrunkAllowedAll: true}
+
+	tests := []struct {
+		name    string
+		from    aoscxgo.L2Interface
+		to      aoscxgo.L2Interface
+		usePut  bool
+		want    aoscxgo.L2Interface
+		wantIds []int
+	}{
+		{
+			name:    "access to trunk",
+			from:    access,
+			to:      trunk,
+			want:    aoscxgo.L2Interface{VlanMode: "native-untagged", VlanTag: 100},
+			wantIds: []int{100, 200},
+		},
+		{
+			name: "trunk to access",
+			from: trunk,
+			to:   access,
+			want: aoscxgo.L2Interface{VlanMode: "access", VlanTag: 100},
+		},
+		{
+			name:    "untagged to tagged native VLAN",
+			from:    trunk,
+			to:      tagged,
+			want:    aoscxgo.L2Interface{VlanMode: "native-tagged", VlanTag: 200, NativeVlanTag: true},
+			wantIds: []int{200},
+		},
+		{
+			name:    "trunk to all VLANs",
+			from:    trunk,
+			to:      all,
+			want:    aoscxgo.L2Interface{VlanMode: "native-untagged", VlanTag: 100, TrunkAllowedAll: true},
+			wantIds: []int{},
+		},
+		{
+			name:    "access to trunk with PUT",
+			from:    access,
+			to:      trunk,
+			usePut:  true,
+			want:    aoscxgo.L2Interface{VlanMode: "native-untagged", VlanTag: 100},
+			wantIds: []int{100, 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			createVlans(t, sw, 100, 200)
+
+			port := aoscxgo.Interface{Name: "1/1/3", AdminState: "up"}
+			tt.from.Interface, tt.to.Interface = port, port
+
+			if err := tt.from.Create(sw); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if err := update(sw, &tt.to, tt.usePut); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			got := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/3"}}
+			if err := got.Get(sw); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.VlanMode != tt.want.VlanMode || got.VlanTag != tt.want.VlanTag ||
+				got.NativeVlanTag != tt.want.NativeVlanTag || got.TrunkAllowedAll != tt.want.TrunkAllowedAll {
+				t.Errorf("Get() = mode %q tag %d native %v all %v, want %+v",
+					got.VlanMode, got.VlanTag, got.NativeVlanTag, got.TrunkAllowedAll, tt.want)
+			}
+			if tt.wantIds != nil && !reflect.DeepEqual(sortedInts(got.VlanIds), tt.wantIds) {
+				t.Errorf("VlanIds = %v, want %v", got.VlanIds, tt.wantIds)
+			}
+			if got.Interface.AdminState != "up" || !got.GetStatus() {
+				t.Errorf("Get() interface = %+v", got.Interface)
+			}
+		})
+	}
+}
+
+func TestL2InterfaceDelete(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	createVlans(t, sw, 100)
+
+	l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/4", AdminState: "up"}, VlanTag: 100}
+	if err := l2.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.Delete(sw); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	stored, _ := srv.Interface("1/1/4")
+	if _, ok := stored["vlan_tag"]; ok {
+		t.Errorf("interface not defaulted by Delete(): %v", stored)
+	}
+
+	missing := aoscxgo.L2Interface{}
+	if err := missing.Delete(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Delete() without interface error = %v, want ErrValidation", err)
+	}
+}
@@ -2,6 +2,7 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,17 +17,28 @@ import (
 type L3Interface struct {
 
 	// Connection properties.
-	Interface        Interface              `json:"interface"`
-	Description      string                 `json:"description"`
-	Ipv4             []interface{}          `json:"ipv4"`
-	Ipv6             []interface{}          `json:"ipv6"`
-	Vrf              string                 `json:"vrf"`
-	InterfaceDetails map[string]interface{} `json:"details"`
-	materialized     bool                   `json:"materialized"`
+	Interface        Interface           `json:"interface"`
+	Description      string              `json:"description"`
+	Ipv4             []string            `json:"ipv4"`
+	Ipv6             []string            `json:"ipv6"`
+	Vrf              string              `json:"vrf"`
+	InterfaceDetails InterfaceAttributes `json:"details"`
+	Mask             FieldMask           `json:"-"`
+	materialized     bool
 }
 
 // Create performs PATCH to update L3Interface configuration on the given Client object.
 func (i *L3Interface) Create(c *Client) error {
+	return i.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (i *L3Interface) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 
 	createMap := map[string]interface{}{}
@@ -34,7 +46,7 @@ func (i *L3Interface) Create(c *Client) error {
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to configure L3Interface",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -45,7 +57,7 @@ func (i *L3Interface) Create(c *Client) error {
 	}
 
 	int_str := url.PathEscape(i.Interface.Name)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
 	createMap["description"] = i.Description
 	createMap["admin"] = i.Interface.AdminState
@@ -64,7 +76,7 @@ func (i *L3Interface) Create(c *Client) error {
 		createMap["ip4_address"] = nil
 		createMap["ip4_address_secondary"] = nil
 	} else if len(i.Ipv4) == 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", i.Ipv4[0])
+		str_ipv4_1 := i.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			createMap["ip4_address"] = str_ipv4_1
 			createMap["ip4_address_secondary"] = nil
@@ -72,40 +84,40 @@ func (i *L3Interface) Create(c *Client) error {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Create Error"),
+				Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 			}
 		}
 
 	} else if len(i.Ipv4) > 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", i.Ipv4[0])
+		str_ipv4_1 := i.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			createMap["ip4_address"] = i.Ipv4[0]
 		} else {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Create Error"),
+				Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 			}
 		}
 
 		var tmp_splice []string
 		for index := 1; index < len(i.Ipv4); index++ {
-			str_ipv4_tmp := fmt.Sprintf("%v", i.Ipv4[index])
+			str_ipv4_tmp := i.Ipv4[index]
 			if checkIPAddress(str_ipv4_tmp) {
 				tmp_splice = append(tmp_splice, str_ipv4_tmp)
 			} else {
 				status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_tmp
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Create Error"),
+					Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 				}
 			}
 		}
 		createMap["ip4_address_secondary"] = tmp_splice
 	}
 
-	// string to track ipv6 Create success
-	failed_ipv6 := ""
+	// track ipv6 Create failures
+	var failed_ipv6 []error
 
 	if len(i.Ipv6) == 0 {
 		// What are default values when no ipv6 but routing enabled
@@ -114,38 +126,38 @@ func (i *L3Interface) Create(c *Client) error {
 		// two values for ipv6? how is that affected
 		// first create POST to add an ipv6 address Object
 		//
-		for _, ip_address := range i.Ipv6 {
-			if _, ok := ip_address.(string); ok {
-				str_ipv6 := fmt.Sprintf("%v", ip_address)
-				if checkIPAddress(str_ipv6) {
-					ipv6Map := map[string]interface{}{}
-					ipv6Map["address"] = str_ipv6
-					ipv6Map["type"] = "global-unicast"
-					ipv6Map["preferred_lifetime"] = 604800
-					ipv6Map["valid_lifetime"] = 2592000
-					ipv6Map["node_address"] = true
-					ipv6Map["ra_prefix"] = true
-					ipv6Map["ra_route"] = false
-
-					ipv6body, _ := json.Marshal(ipv6Map)
-
-					json_body := bytes.NewBuffer(ipv6body)
-
-					ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
-
-					res := post(c, ip6_url, json_body)
-
-					if res.StatusCode != http.StatusCreated {
-						failed_ipv6 = fmt.Sprintf("\nip6_addresses failed to create %v\nstatus code %v", i.Ipv6, res.Status)
-					}
-
-				} else {
-					status_str := "Invalid Required Value: Ipv6 - ensure addresses are in ipv6 address/mask format:" +
-						ip_address.(string)
-					return &RequestError{
-						StatusCode: status_str,
-						Err:        errors.New("Create Error"),
-					}
+		for _, str_ipv6 := range i.Ipv6 {
+			if checkIPAddress(str_ipv6) {
+				ipv6Map := map[string]interface{}{}
+				ipv6Map["address"] = str_ipv6
+				ipv6Map["type"] = "global-unicast"
+				ipv6Map["preferred_lifetime"] = 604800
+				ipv6Map["valid_lifetime"] = 2592000
+				ipv6Map["node_address"] = true
+				ipv6Map["ra_prefix"] = true
+				ipv6Map["ra_route"] = false
+
+				ipv6body, _ := json.Marshal(ipv6Map)
+
+				json_body := bytes.NewBuffer(ipv6body)
+
+				ip6_url := c.restURL() + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
+
+				res, err := post(ctx, c, ip6_url, json_body)
+				if err != nil {
+					return err
+				}
+
+				if res.StatusCode != http.StatusCreated {
+					failed_ipv6 = append(failed_ipv6, newAPIError("ip6_addresses failed to create "+str_ipv6, res))
+				}
+
+			} else {
+				status_str := "Invalid Required Value: Ipv6 - ensure addresses are in ipv6 address/mask format:" +
+					str_ipv6
+				return &RequestError{
+					StatusCode: status_str,
+					Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 				}
 			}
 		}
@@ -165,13 +177,13 @@ func (i *L3Interface) Create(c *Client) error {
 		Name: i.Interface.Name,
 	}
 
-	err = tmp_int.Get(c)
+	err = tmp_int.GetContext(ctx, c)
 
 	if err != nil {
 		return err
 
 	} else if !tmp_int.materialized {
-		err = i.Interface.Create(c)
+		err = i.Interface.CreateContext(ctx, c)
 
 		if err != nil {
 			return err
@@ -183,26 +195,16 @@ func (i *L3Interface) Create(c *Client) error {
 
 	json_body := bytes.NewBuffer(patchBody)
 
-	res := patch(c, url, json_body)
+	res, err := patch(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
-	if res.StatusCode != http.StatusNoContent && failed_ipv6 != "" {
-		// Combine error messages
-		status_str := "L3Interface Create Failed\nstatus " + res.Status
-		status_str = status_str + failed_ipv6
-		return &RequestError{
-			StatusCode: status_str,
-			Err:        errors.New("Create Error"),
-		}
-	} else if res.StatusCode != http.StatusNoContent {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Create Error"),
-		}
-	} else if failed_ipv6 != "" {
-		return &RequestError{
-			StatusCode: failed_ipv6,
-			Err:        errors.New("Create Error"),
-		}
+	if res.StatusCode != http.StatusNoContent {
+		// Combine errors so the ip6_addresses failures are not lost
+		return errors.Join(append([]error{newAPIError("Create Error", res)}, failed_ipv6...)...)
+	} else if len(failed_ipv6) > 0 {
+		return errors.Join(failed_ipv6...)
 	}
 
 	i.materialized = true
@@ -210,8 +212,63 @@ func (i *L3Interface) Create(c *Client) error {
 	return nil
 }
 
-// Update performs PATCH or PUT to update L3Interface configuration on the given Client object.
-func (i *L3Interface) Update(c *Client, use_put bool) error {
+// Update performs PATCH to update L3Interface configuration on the given Client object.
+// Only the attributes that differ from the switch are sent, and addresses are
+// left unchanged when Ipv4 or Ipv6 is empty unless the attribute is in Mask.
+func (i *L3Interface) Update(c *Client) error {
+	return i.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (i *L3Interface) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", i.Kind(), i.Key())
+	return i.update(ctx, c, false)
+}
+
+// Replace performs PUT to replace L3Interface configuration on the given Client object.
+// Writable attributes not managed by the L3Interface are read first and sent back unchanged.
+func (i *L3Interface) Replace(c *Client) error {
+	return i.ReplaceContext(context.Background(), c)
+}
+
+// ReplaceContext is like Replace but uses ctx for every request made to the switch.
+func (i *L3Interface) ReplaceContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "replace", i.Kind(), i.Key())
+	return i.update(ctx, c, true)
+}
+
+// update sends the configuration with PATCH, or with PUT when use_put is set.
+func (i *L3Interface) update(ctx context.Context, c *Client, use_put bool) error {
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
+	if i.Interface.Name == "" {
+		return &RequestError{
+			StatusCode: "Missing Interface.Name unable to configure L3Interface",
+			Err:        fmt.Errorf("Update Error: %w", ErrValidation),
+		}
+	}
+
+	err := i.Interface.checkValues()
+	if err != nil {
+		return err
+
+	}
+
+	// A PATCH only carries the attributes that differ from the switch
+	var changed []Difference
+	if !use_put {
+		changed, err = changes(ctx, c, i)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			i.materialized = true
+			return nil
+		}
+	}
+
 	base_uri := "system/interfaces"
 
 	updateMap := map[string]interface{}{}
@@ -219,12 +276,12 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 
 	if use_put {
 		tmp_l3_int := L3Interface{Interface: i.Interface}
-		err := tmp_l3_int.Get(c)
+		err := tmp_l3_int.GetContext(ctx, c)
 		if err != nil {
 			return err
 		}
 
-		for key, value := range tmp_l3_int.InterfaceDetails {
+		for key, value := range tmp_l3_int.InterfaceDetails.values() {
 			updateMap[key] = value
 		}
 	}
@@ -242,7 +299,7 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 		updateMap["ip4_address"] = nil
 		updateMap["ip4_address_secondary"] = nil
 	} else if len(i.Ipv4) == 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", i.Ipv4[0])
+		str_ipv4_1 := i.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			updateMap["ip4_address"] = str_ipv4_1
 			updateMap["ip4_address_secondary"] = nil
@@ -250,102 +307,80 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 			}
 		}
 
 	} else if len(i.Ipv4) > 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", i.Ipv4[0])
+		str_ipv4_1 := i.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			updateMap["ip4_address"] = i.Ipv4[0]
 		} else {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 			}
 		}
 
 		var tmp_splice []string
 		for index := 1; index < len(i.Ipv4); index++ {
-			str_ipv4_tmp := fmt.Sprintf("%v", i.Ipv4[index])
+			str_ipv4_tmp := i.Ipv4[index]
 			if checkIPAddress(str_ipv4_tmp) {
 				tmp_splice = append(tmp_splice, str_ipv4_tmp)
 			} else {
 				status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_tmp
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Update Error"),
+					Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 				}
 			}
 		}
 		updateMap["ip4_address_secondary"] = tmp_splice
 	}
 
-	if len(i.Ipv6) == 0 {
+	if !use_put && !hasDifference(changed, "ip6_addresses") {
+		// The ip6_addresses are left as they are
+	} else if len(i.Ipv6) == 0 {
 		// What are default values when no ipv6 but routing enabled
 		updateMap["ip6_addresses"] = nil
 		// retrieve what's existing on the switch and remove
-		ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
-		res, body := get(c, ip6_url)
-
-		if res.StatusCode != http.StatusOK {
-			return &RequestError{
-				StatusCode: res.Status,
-				Err:        errors.New("Retrieval Error"),
-			}
-		}
-		for key, _ := range body {
-			tmp_ip6_str := url.QueryEscape(key)
-			del_ip6_url := ip6_url + "/" + tmp_ip6_str
-			res := delete(c, del_ip6_url)
-
-			if res.StatusCode != http.StatusNoContent {
-				return &RequestError{
-					StatusCode: res.Status,
-					Err:        errors.New("Delete Error"),
-				}
-			}
+		if err := deleteIP6Addresses(ctx, c, int_str); err != nil {
+			return err
 		}
 	} else if len(i.Ipv6) > 0 {
 		// two values for ipv6? how is that affected
 		// first create POST to add an ipv6 address Object
 		//
-		ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
+		ip6_url := c.restURL() + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
 
 		// execute GET to retrieve current IPs
 		// iterate over the list of IPs
 		// delete the ones that aren't provided
 
-		res, body := get(c, ip6_url)
+		res, body, err := get(ctx, c, ip6_url)
+		if err != nil {
+			return err
+		}
 
 		if res.StatusCode != http.StatusOK {
-			return &RequestError{
-				StatusCode: res.Status,
-				Err:        errors.New("Retrieval Error"),
-			}
+			return newAPIError("Retrieval Error", res)
 		}
 
-		var ipv6_slice []string
-		// convert i.Ipv6 from []interface{} to []string
-		for _, ip_address := range i.Ipv6 {
-			if _, ok := ip_address.(string); ok {
-				ipv6_slice = append(ipv6_slice, ip_address.(string))
-			}
-		}
+		ipv6_slice := i.Ipv6
 
 		var get_ipv6_slice []string
 		for key, _ := range body {
 			if !slices.Contains(ipv6_slice, key) {
 				tmp_ip6_str := url.QueryEscape(key)
 				del_ip6_url := ip6_url + "/" + tmp_ip6_str
-				res := delete(c, del_ip6_url)
+				res, err := delete(ctx, c, del_ip6_url)
+				if err != nil {
+					return err
+				}
 
 				if res.StatusCode != http.StatusNoContent {
-					return &RequestError{
-						StatusCode: res.Status,
-						Err:        errors.New("Delete Error"),
-					}
+					return newAPIError("Delete Error", res)
 				}
 
 			} else {
@@ -368,16 +403,14 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 
 				json_body := bytes.NewBuffer(ipv6body)
 
-				res := post(c, ip6_url, json_body)
+				res, err := post(ctx, c, ip6_url, json_body)
+				if err != nil {
+					return err
+				}
 
 				// include logic to check if address is existing?
 				if res.StatusCode != http.StatusCreated {
-					status_str := "ip6_addresses failed to update " +
-						str_ipv6 + " status " + res.Status
-					return &RequestError{
-						StatusCode: status_str,
-						Err:        errors.New("Update Error"),
-					}
+					return newAPIError("ip6_addresses failed to update "+str_ipv6, res)
 				}
 
 			} else if !checkIPAddress(str_ipv6) {
@@ -385,7 +418,7 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 					str_ipv6
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Update Error"),
+					Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 				}
 			}
 		}
@@ -399,20 +432,7 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 			"admin": "up"}
 	}
 
-	if i.Interface.Name == "" {
-		return &RequestError{
-			StatusCode: "Missing Interface.Name unable to configure L3Interface",
-			Err:        errors.New("Update Error"),
-		}
-	}
-
-	err := i.Interface.checkValues()
-	if err != nil {
-		return err
-
-	}
-
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
 	updateMap["description"] = i.Description
 	updateMap["admin"] = i.Interface.AdminState
@@ -429,30 +449,30 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 			"admin": "up"}
 	}
 
+	if !use_put {
+		updateMap = changedBody(updateMap, changed)
+	}
+
 	updateBody, _ := json.Marshal(updateMap)
 
 	json_body := bytes.NewBuffer(updateBody)
 
 	if use_put {
-		res := put(c, url, json_body)
+		res, err := put(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.StatusCode != http.StatusOK {
-			status_str := string(updateBody) + "\nPUT status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 
 	} else {
-		res := patch(c, url, json_body)
+		res, err := patch(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.StatusCode != http.StatusNoContent {
-			status_str := string(updateBody) + "\nPATCH status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 	}
 
@@ -462,107 +482,139 @@ func (i *L3Interface) Update(c *Client, use_put bool) error {
 }
 
 // Delete performs PUT to remove/default L3Interface configuration from the given Client object.
+// Its IPv6 addresses are deleted first, as the PUT does not reset them.
 func (i *L3Interface) Delete(c *Client) error {
+	return i.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (i *L3Interface) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", i.Kind(), i.Key())
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to delete L3Interface",
-			Err:        errors.New("Delete Error"),
+			Err:        fmt.Errorf("Delete Error: %w", ErrValidation),
 		}
 	}
 	int_str := url.PathEscape(i.Interface.Name)
 
+	// ip6_addresses is a separate table and is not reset by the PUT
+	if err := deleteIP6Addresses(ctx, c, int_str); err != nil {
+		return err
+	}
+
 	putMap := map[string]interface{}{}
 
 	putBody, _ := json.Marshal(putMap)
 
 	json_body := bytes.NewBuffer(putBody)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str
+	url := c.restURL() + "/" + base_uri + "/" + int_str
 
-	res := put(c, url, json_body)
+	res, err := put(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Delete Error"),
-		}
+		return newAPIError("Delete Error", res)
 	}
 
 	return nil
 }
 
+// deleteIP6Addresses removes every IPv6 address of the interface int_str, which
+// must already be escaped.
+func deleteIP6Addresses(ctx context.Context, c *Client, int_str string) error {
+	ip6_url := c.restURL() + "/system/interfaces/" + int_str + "/ip6_addresses"
+	res, body, err := get(ctx, c, ip6_url)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return newAPIError("Retrieval Error", res)
+	}
+	for key, _ := range body {
+		tmp_ip6_str := url.QueryEscape(key)
+		del_ip6_url := ip6_url + "/" + tmp_ip6_str
+		res, err := delete(ctx, c, del_ip6_url)
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode != http.StatusNoContent {
+			return newAPIError("Delete Error", res)
+		}
+	}
+	return nil
+}
+
 // Get performs GET to retrieve L3Interface configuration from the given Client object.
 func (i *L3Interface) Get(c *Client) error {
+	return i.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (i *L3Interface) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityInterfaces, CapabilityIP6Addresses, CapabilityWritableSelector); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if i.Interface.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface unable to configure L3Interface",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 	int_str := url.PathEscape(i.Interface.Name)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "?selector=writable"
-
-	res, body := get(c, url)
+	url := c.restURL() + "/" + base_uri + "/" + int_str + "?selector=writable"
 
-	if res.StatusCode != http.StatusOK {
+	var attributes InterfaceAttributes
+	if err := getJSON(ctx, c, "Retrieval Error", url, &attributes); err != nil {
 		i.materialized = false
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Retrieval Error"),
-		}
+		return err
 	}
 
-	if i.Interface.InterfaceDetails == nil {
-		i.Interface.InterfaceDetails = map[string]interface{}{}
+	i.InterfaceDetails = attributes
+	i.Interface.InterfaceDetails = attributes
+	if attributes.Has("description") {
+		i.Description = attributes.Description
+		i.Interface.Description = attributes.Description
 	}
 
-	for key, value := range body {
-		i.Interface.InterfaceDetails[key] = value
-		if key == "description" && value != nil {
-			i.Description = value.(string)
-			i.Interface.Description = value.(string)
-		}
-
-		if key == "admin" && value != nil {
-			i.Interface.AdminState = value.(string)
-		}
-
-		if key == "ip4_address" && value != nil {
-			var tmp_splice []interface{}
-			tmp_splice = append(tmp_splice, value.(string))
-			if val, ok := body["ip4_address_secondary"]; ok {
-				tmp_addr := val.([]interface{})
-				if len(tmp_addr) > 0 {
-					for index := 0; index < len(tmp_addr); index++ {
-						tmp_splice = append(tmp_splice, tmp_addr[index])
-					}
-				}
-			}
-			i.Ipv4 = tmp_splice
-		}
+	if attributes.Has("admin") {
+		i.Interface.AdminState = attributes.Admin
+	}
 
-		if key == "vrf" && value != nil {
-			for key, _ := range value.(map[string]interface{}) {
-				i.Vrf = key
-			}
-		}
+	if attributes.Ip4Address != "" {
+		tmp_splice := []string{attributes.Ip4Address}
+		tmp_splice = append(tmp_splice, attributes.Ip4AddressSecondary...)
+		i.Ipv4 = tmp_splice
+	}
 
+	for _, key := range attributes.Vrf.Keys() {
+		i.Vrf = key
 	}
 
 	// Include a GET for ip6 and populate .ipv6 attribute
 
-	ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
+	ip6_url := c.restURL() + "/" + base_uri + "/" + int_str + "/" + "ip6_addresses"
 
-	res, body = get(c, ip6_url)
+	res, body, err := get(ctx, c, ip6_url)
+	if err != nil {
+		return err
+	}
 
 	if res.StatusCode != http.StatusOK {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Retrieval Error"),
-		}
+		return newAPIError("Retrieval Error", res)
 	}
 
 	var ipv6_slice []string
@@ -573,13 +625,8 @@ func (i *L3Interface) Get(c *Client) error {
 		}
 	}
 
-	ip6_addresses := make([]interface{}, len(ipv6_slice))
-
-	if len(ipv6_slice) > 0 {
-		for index := 0; index < len(ipv6_slice); index++ {
-			ip6_addresses[index] = ipv6_slice[index]
-		}
-	}
+	ip6_addresses := make([]string, len(ipv6_slice))
+	copy(ip6_addresses, ipv6_slice)
 
 	i.Ipv6 = ip6_addresses
 
@@ -593,6 +640,31 @@ func (i *L3Interface) GetStatus() bool {
 	return i.materialized
 }
 
+// Kind returns "L3Interface".
+func (i *L3Interface) Kind() string {
+	return "L3Interface"
+}
+
+// Key returns the name of the underlying Interface.
+func (i *L3Interface) Key() string {
+	return i.Interface.Name
+}
+
+// URI returns the path of the L3Interface relative to the versioned REST API.
+func (i *L3Interface) URI() string {
+	return interfaceURI(i.Interface.Name)
+}
+
+// Exists reports whether its interface is present on the switch.
+func (i *L3Interface) Exists(c *Client) (bool, error) {
+	return i.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (i *L3Interface) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, i, CapabilityInterfaces)
+}
+
 func checkIPAddress(ip string) bool {
 	if strings.Contains(ip, "/") {
 		_, _, err := net.ParseCIDR(ip)
@@ -608,3 +680,56 @@ func checkIPAddress(ip string) bool {
 		return true
 	}
 }
+
+// Ensure configures the L3Interface when its description, admin state, VRF or addresses differ from the switch.
+func (i *L3Interface) Ensure(c *Client) (EnsureResult, error) {
+	return i.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (i *L3Interface) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, i)
+}
+
+// EnsureAbsent resets the L3Interface with Delete unless its port has no configuration left.
+func (i *L3Interface) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return i.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (i *L3Interface) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureDefault(ctx, c, i)
+}
+
+// state returns the attributes of the L3Interface, see stateful. Routing is
+// always enabled and the VLAN attributes removed by Update.
+func (i *L3Interface) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, i.Mask)
+	state.add("description", i.Description, i.Description != "")
+	state.add("admin", i.Interface.AdminState, i.Interface.AdminState != "")
+	if desired {
+		state.add("routing", true, true)
+		state.add("vlan_mode", "", true)
+		state.add("vlan_tag", 0, true)
+	} else {
+		vlan_tag := 0
+		if tags := i.InterfaceDetails.VlanTag.IntKeys(); len(tags) > 0 {
+			vlan_tag = tags[0]
+		}
+		state.add("routing", i.InterfaceDetails.Routing, true)
+		state.add("vlan_mode", i.InterfaceDetails.VlanMode, true)
+		state.add("vlan_tag", vlan_tag, true)
+	}
+	state.addRoutedState(i.Vrf, i.Ipv4, i.Ipv6)
+	return state.values
+}
+
+// writableState reads the writable attributes and ip6_addresses of the
+// interface from the switch.
+func (i *L3Interface) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	current := L3Interface{Interface: Interface{Name: i.Interface.Name}}
+	if err := current.GetContext(ctx, c); err != nil {
+		return nil, err
+	}
+	return current.state(false), nil
+}
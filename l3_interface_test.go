@@ -0,0 +1,263 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestCheckIPAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.0.1", true},
+		{"10.0.0.1/24", true},
+		{"192.168.1.0/31", true},
+		{"2001:db8::1", true},
+		{"2001:db8::1/64", true},
+		{"10.0.0.256", false},
+		{"10.0.0.1/33", false},
+		{"2001:db8::1/129", false},
+		{"10.0.0", false},
+		{"not-an-ip", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := aoscxgo.CheckIPAddress(tt.ip); got != tt.want {
+				t.Errorf("checkIPAddress(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestL3InterfaceCreate(t *testing.T) {
+	tests := []struct {
+		name          string
+		l3            aoscxgo.L3Interface
+		wantErr       error
+		wantPrimary   interface{}
+		wantSecondary []interface{}
+		wantVrf       string
+	}{
+		{
+			name:        "single IPv4",
+			l3:          aoscxgo.L3Interface{Ipv4: []string{"10.0.0.1/24"}},
+			wantPrimary: "10.0.0.1/24",
+			wantVrf:     "default",
+		},
+		{
+			name:          "secondary IPv4 addresses",
+			l3:            aoscxgo.L3Interface{Ipv4: []string{"10.0.0.1/24", "10.0.1.1/24", "10.0.2.1/24"}},
+			wantPrimary:   "10.0.0.1/24",
+			wantSecondary: []interface{}{"10.0.1.1/24", "10.0.2.1/24"},
+			wantVrf:       "default",
+		},
+		{
+			name:    "IPv6 only in VRF",
+			l3:      aoscxgo.L3Interface{Ipv6: []string{"2001:db8::1/64", "2001:db8:1::1/64"}, Vrf: "mgmt"},
+			wantVrf: "mgmt",
+		},
+		{
+			name:    "invalid primary IPv4",
+			l3:      aoscxgo.L3Interface{Ipv4: []string{"10.0.0.300/24"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "invalid secondary IPv4",
+			l3:      aoscxgo.L3Interface{Ipv4: []string{"10.0.0.1/24", "bogus"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "invalid IPv6",
+			l3:      aoscxgo.L3Interface{Ipv6: []string{"2001:db8::zz/64"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+
+			tt.l3.Interface = aoscxgo.Interface{Name: "1/1/5", AdminState: "up"}
+			tt.l3.Description = "routed"
+
+			err := tt.l3.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored, _ := srv.Interface("1/1/5")
+			if stored["routing"] != true || stored["description"] != "routed" {
+				t.Errorf("stored interface = %v", stored)
+			}
+			if stored["ip4_address"] != tt.wantPrimary {
+				t.Errorf("ip4_address = %v, want %v", stored["ip4_address"], tt.wantPrimary)
+			}
+			if secondary, _ := stored["ip4_address_secondary"].([]interface{}); !reflect.DeepEqual(secondary, tt.wantSecondary) {
+				t.Errorf("ip4_address_secondary = %v, want %v", secondary, tt.wantSecondary)
+			}
+			if got := referenceKeys(stored["vrf"]); !reflect.DeepEqual(got, []string{tt.wantVrf}) {
+				t.Errorf("vrf = %v, want %q", stored["vrf"], tt.wantVrf)
+			}
+
+			want_ipv6 := sortedStrings(tt.l3.Ipv6)
+			got_ipv6 := srv.IP6Addresses("1/1/5")
+			sort.Strings(got_ipv6)
+			if len(want_ipv6) > 0 || len(got_ipv6) > 0 {
+				if !reflect.DeepEqual(got_ipv6, want_ipv6) {
+					t.Errorf("ip6_addresses = %v, want %v", got_ipv6, want_ipv6)
+				}
+			}
+		})
+	}
+}
+
+func TestL3InterfaceGet(t *testing.T) {
+	_, sw := newTestSwitch(t)
+
+	created := aoscxgo.L3Interface{
+		Interface:   aoscxgo.Interface{Name: "1/1/6", AdminState: "up"},
+		Description: "uplink",
+		Ipv4:        []string{"10.0.0.1/24", "10.0.1.1/24"},
+		Ipv6:        []string{"2001:db8::1/64"},
+		Vrf:         "mgmt",
+	}
+	if err := created.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+
+	got := aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/6"}}
+	if err := got.Get(sw); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Description != "uplink" || got.Vrf != "mgmt" || got.Interface.AdminState != "up" || !got.GetStatus() {
+		t.Errorf("Get() = %+v", got)
+	}
+	if !reflect.DeepEqual(got.Ipv4, created.Ipv4) {
+		t.Errorf("Ipv4 = %v, want %v", got.Ipv4, created.Ipv4)
+	}
+	if !reflect.DeepEqual(got.Ipv6, created.Ipv6) {
+		t.Errorf("Ipv6 = %v, want %v", got.Ipv6, created.Ipv6)
+	}
+
+	missing := aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/99"}}
+	if err := missing.Get(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Get() missing interface error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestL3InterfaceUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		ipv4     []string
+		ipv6     []string
+		mask     aoscxgo.FieldMask
+		usePut   bool
+		wantErr  error
+		wantIpv6 []string
+	}{
+		{
+			name:     "replace IPv6 addresses",
+			ipv4:     []string{"10.0.0.1/24"},
+			ipv6:     []string{"2001:db8::1/64", "2001:db8:2::1/64"},
+			wantIpv6: []string{"2001:db8:2::1/64", "2001:db8::1/64"},
+		},
+		{
+			name:     "keep IPv6 addresses",
+			ipv4:     []string{"10.0.0.1/24"},
+			wantIpv6: []string{"2001:db8:1::1/64", "2001:db8::1/64"},
+		},
+		{
+			name:     "remove all IPv6 addresses",
+			ipv4:     []string{"10.0.0.1/24"},
+			mask:     aoscxgo.FieldMask{"ip6_addresses"},
+			wantIpv6: []string{},
+		},
+		{
+			name:     "with PUT",
+			ipv4:     []string{"10.0.5.1/24"},
+			ipv6:     []string{"2001:db8::1/64"},
+			usePut:   true,
+			wantIpv6: []string{"2001:db8::1/64"},
+		},
+		{
+			name:    "invalid IPv4",
+			ipv4:    []string{"10.0.0.1/40"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "invalid IPv6",
+			ipv6:    []string{"2001:db8::1/64", "bogus"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+
+			port := aoscxgo.Interface{Name: "1/1/7", AdminState: "up"}
+			created := aoscxgo.L3Interface{
+				Interface: port,
+				Ipv4:      []string{"10.0.0.1/24"},
+				Ipv6:      []string{"2001:db8::1/64", "2001:db8:1::1/64"},
+			}
+			if err := created.Create(sw); err != nil {
+				t.Fatal(err)
+			}
+
+			l3 := aoscxgo.L3Interface{Interface: port, Description: "updated", Ipv4: tt.ipv4, Ipv6: tt.ipv6, Mask: tt.mask}
+			err := update(sw, &l3, tt.usePut)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got_ipv6 := srv.IP6Addresses("1/1/7")
+			sort.Strings(got_ipv6)
+			if len(got_ipv6) != len(tt.wantIpv6) || (len(got_ipv6) > 0 && !reflect.DeepEqual(got_ipv6, tt.wantIpv6)) {
+				t.Errorf("ip6_addresses = %v, want %v", got_ipv6, tt.wantIpv6)
+			}
+
+			got := aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/7"}}
+			if err := got.Get(sw); err != nil {
+				t.Fatal(err)
+			}
+			if got.Description != "updated" || !reflect.DeepEqual(got.Ipv4, tt.ipv4) {
+				t.Errorf("Get() after Update = %+v", got)
+			}
+		})
+	}
+}
+
+func TestL3InterfaceDelete(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+
+	l3 := aoscxgo.L3Interface{Interface: aoscxgo.Interface{Name: "1/1/8", AdminState: "up"}, Ipv4: []string{"10.0.0.1/24"}}
+	if err := l3.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := l3.Delete(sw); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	stored, _ := srv.Interface("1/1/8")
+	if _, ok := stored["ip4_address"]; ok {
+		t.Errorf("interface not defaulted by Delete(): %v", stored)
+	}
+
+	missing := aoscxgo.L3Interface{}
+	if err := missing.Delete(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Delete() without interface error = %v, want ErrValidation", err)
+	}
+}
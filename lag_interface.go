@@ -2,8 +2,9 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -13,27 +14,28 @@ import (
 type LagInterface struct {
 
 	// Connection properties.
-	Name             string                 `json:"name"`
-	Description      string                 `json:"description"`
-	AdminState       string                 `json:"admin"`
-	VlanMode         string                 `json:"vlan_mode"`
-	VlanIds          []interface{}          `json:"vlan_ids"`
-	VlanTag          int                    `json:"vlan_tag"`
-	TrunkAllowedAll  bool                   `json:"trunk_allowed_all"`
-	NativeVlanTag    bool                   `json:"native_vlan_tag"`
-	LacpMode         string                 `json:"lacp_mode"`
-	InterfaceDetails map[string]interface{} `json:"details"`
-	materialized     bool                   `json:"materialized"`
-	uri              string                 `json:"uri"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	AdminState       string              `json:"admin"`
+	VlanMode         string              `json:"vlan_mode"`
+	VlanIds          []int               `json:"vlan_ids"`
+	VlanTag          int                 `json:"vlan_tag"`
+	TrunkAllowedAll  bool                `json:"trunk_allowed_all"`
+	NativeVlanTag    bool                `json:"native_vlan_tag"`
+	LacpMode         string              `json:"lacp_mode"`
+	InterfaceDetails InterfaceAttributes `json:"details"`
+	Mask             FieldMask           `json:"-"`
+	materialized     bool
+	uri              string
 }
 
-// checkValues validates LAG interface configuration
-func (l *LagInterface) checkValues() error {
+// checkValues validates LAG interface configuration, reporting failures as op
+func (l *LagInterface) checkValues(op string) error {
 	// Validate LAG name format
 	if matched, _ := regexp.MatchString("^lag\\d+$", l.Name); !matched {
 		return &RequestError{
 			StatusCode: "Invalid Required Value: Name - must be in format 'lagXX' (e.g., lag60)",
-			Err:        errors.New("validation error"),
+			Err:        fmt.Errorf("%s: %w", op, ErrValidation),
 		}
 	}
 
@@ -41,7 +43,7 @@ func (l *LagInterface) checkValues() error {
 	if l.AdminState != "up" && l.AdminState != "down" {
 		return &RequestError{
 			StatusCode: "Invalid Required Value: AdminState - valid options are 'up' or 'down' received: " + l.AdminState,
-			Err:        errors.New("validation error"),
+			Err:        fmt.Errorf("%s: %w", op, ErrValidation),
 		}
 	}
 
@@ -49,7 +51,7 @@ func (l *LagInterface) checkValues() error {
 	if l.LacpMode != "" && l.LacpMode != "active" && l.LacpMode != "passive" {
 		return &RequestError{
 			StatusCode: "Invalid Required Value: LacpMode - valid options are 'active' or 'passive' received: " + l.LacpMode,
-			Err:        errors.New("validation error"),
+			Err:        fmt.Errorf("%s: %w", op, ErrValidation),
 		}
 	}
 
@@ -57,16 +59,16 @@ func (l *LagInterface) checkValues() error {
 }
 
 // ensureVlanExists checks if VLAN exists, creates it if not
-func (l *LagInterface) ensureVlanExists(c *Client, vlanId int) (*Vlan, error) {
+func (l *LagInterface) ensureVlanExists(ctx context.Context, c *Client, vlanId int, op string) (*Vlan, error) {
 	vlan := &Vlan{VlanId: vlanId}
-	err := vlan.Get(c)
+	err := vlan.GetContext(ctx, c)
 
 	if err != nil && !vlan.materialized {
-		err = vlan.Create(c)
+		err = vlan.CreateContext(ctx, c)
 		if err != nil && !vlan.materialized {
 			return nil, &RequestError{
 				StatusCode: "VLAN " + strconv.Itoa(vlanId) + " not found and unable to create",
-				Err:        errors.New("vlan dependency error"),
+				Err:        fmt.Errorf("%s: %w", op, ErrDependency),
 			}
 		}
 	}
@@ -74,7 +76,7 @@ func (l *LagInterface) ensureVlanExists(c *Client, vlanId int) (*Vlan, error) {
 }
 
 // buildVlanConfig constructs VLAN configuration for the interface
-func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error) {
+func (l *LagInterface) buildVlanConfig(ctx context.Context, c *Client, op string) (map[string]interface{}, error) {
 	config := make(map[string]interface{})
 
 	if l.VlanMode == "" || l.VlanMode == "access" {
@@ -84,7 +86,7 @@ func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error
 			vlanId = 1
 		}
 
-		vlan, err := l.ensureVlanExists(c, vlanId)
+		vlan, err := l.ensureVlanExists(ctx, c, vlanId, op)
 		if err != nil {
 			return nil, err
 		}
@@ -93,14 +95,16 @@ func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error
 		config["vlan_mode"] = "access"
 
 	} else if l.VlanMode == "trunk" || l.VlanMode == "native-untagged" || l.VlanMode == "native-tagged" {
-		// Trunk mode configuration
+		// Trunk mode configuration, the switch only accepts the native modes
+		l.VlanMode = trunkVlanMode(l.VlanMode, l.NativeVlanTag)
+		l.NativeVlanTag = l.VlanMode == "native-tagged"
 		config["vlan_mode"] = l.VlanMode
 
 		// Configure native VLAN
 		if l.VlanTag == 0 || l.VlanTag == 1 {
 			config["vlan_tag"] = nil
 		} else {
-			vlan, err := l.ensureVlanExists(c, l.VlanTag)
+			vlan, err := l.ensureVlanExists(ctx, c, l.VlanTag, op)
 			if err != nil {
 				return nil, err
 			}
@@ -110,9 +114,8 @@ func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error
 		// Configure trunk VLANs
 		vlanTrunks := make(map[string]interface{})
 		if len(l.VlanIds) > 0 {
-			for _, item := range l.VlanIds {
-				vlanId := item.(int)
-				vlan, err := l.ensureVlanExists(c, vlanId)
+			for _, vlanId := range l.VlanIds {
+				vlan, err := l.ensureVlanExists(ctx, c, vlanId, op)
 				if err == nil {
 					vlanTrunks[strconv.Itoa(vlanId)] = vlan.GetURI()
 				}
@@ -123,7 +126,7 @@ func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error
 	} else {
 		return nil, &RequestError{
 			StatusCode: "Invalid VlanMode: " + l.VlanMode + " - valid options are 'access', 'trunk', 'native-untagged', or 'native-tagged'",
-			Err:        errors.New("validation error"),
+			Err:        fmt.Errorf("%s: %w", op, ErrValidation),
 		}
 	}
 
@@ -132,12 +135,22 @@ func (l *LagInterface) buildVlanConfig(c *Client) (map[string]interface{}, error
 
 // Create performs POST to create LAG Interface configuration
 func (l *LagInterface) Create(c *Client) error {
-	if err := l.checkValues(); err != nil {
+	return l.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (l *LagInterface) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", l.Kind(), l.Key())
+	if err := c.requireCapability(CapabilityLagInterfaces); err != nil {
+		return err
+	}
+
+	if err := l.checkValues("Create Error"); err != nil {
 		return err
 	}
 
 	baseURI := "system/interfaces"
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + baseURI
+	url := c.restURL() + "/" + baseURI
 	l.uri = "/rest/" + c.Version + "/" + baseURI + "/" + l.Name
 
 	// Build base configuration
@@ -155,7 +168,7 @@ func (l *LagInterface) Create(c *Client) error {
 
 	// Add VLAN configuration
 	if l.VlanMode != "" {
-		vlanConfig, err := l.buildVlanConfig(c)
+		vlanConfig, err := l.buildVlanConfig(ctx, c, "Create Error")
 		if err != nil {
 			return err
 		}
@@ -168,44 +181,86 @@ func (l *LagInterface) Create(c *Client) error {
 	postBody, _ := json.Marshal(postMap)
 	jsonBody := bytes.NewBuffer(postBody)
 
-	res := post(c, url, jsonBody)
+	res, err := post(ctx, c, url, jsonBody)
+	if err != nil {
+		return err
+	}
 	if res.Status != "201 Created" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("create error"),
-		}
+		return newAPIError("create error", res)
 	}
 
 	l.materialized = true
 	return nil
 }
 
-// Update performs PATCH or PUT to update LAG Interface configuration
-func (l *LagInterface) Update(c *Client, usePut bool) error {
-	if err := l.checkValues(); err != nil {
+// Update performs PATCH to update LAG Interface configuration
+// Only the attributes that differ from the switch are sent, empty fields are skipped unless in Mask
+func (l *LagInterface) Update(c *Client) error {
+	return l.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (l *LagInterface) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", l.Kind(), l.Key())
+	return l.update(ctx, c, false)
+}
+
+// Replace performs PUT to replace LAG Interface configuration
+// Writable attributes not managed by the LagInterface are read first and sent back unchanged.
+func (l *LagInterface) Replace(c *Client) error {
+	return l.ReplaceContext(context.Background(), c)
+}
+
+// ReplaceContext is like Replace but uses ctx for every request made to the switch.
+func (l *LagInterface) ReplaceContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "replace", l.Kind(), l.Key())
+	return l.update(ctx, c, true)
+}
+
+// update sends the configuration with PATCH, or with PUT when usePut is set.
+func (l *LagInterface) update(ctx context.Context, c *Client, usePut bool) error {
+	if err := c.requireCapability(CapabilityLagInterfaces); err != nil {
+		return err
+	}
+
+	if err := l.checkValues("Update Error"); err != nil {
 		return err
 	}
 
 	if l.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface Name",
-			Err:        errors.New("update error"),
+			Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 		}
 	}
 
 	baseURI := "system/interfaces"
 	intStr := url.PathEscape(l.Name)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + baseURI + "/" + intStr
+	url := c.restURL() + "/" + baseURI + "/" + intStr
+
+	// For PATCH, only send what differs from the switch
+	var changed []Difference
+	if !usePut {
+		var err error
+		changed, err = changes(ctx, c, l)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			l.materialized = true
+			return nil
+		}
+	}
 
 	updateMap := make(map[string]interface{})
 
 	// For PUT, get existing configuration
 	if usePut {
 		tmpLag := LagInterface{Name: l.Name}
-		if err := tmpLag.Get(c); err != nil {
+		if err := tmpLag.GetContext(ctx, c); err != nil {
 			return err
 		}
-		for key, value := range tmpLag.InterfaceDetails {
+		for key, value := range tmpLag.InterfaceDetails.values() {
 			updateMap[key] = value
 		}
 	}
@@ -220,8 +275,8 @@ func (l *LagInterface) Update(c *Client, usePut bool) error {
 	}
 
 	// Add VLAN configuration
-	if l.VlanMode != "" {
-		vlanConfig, err := l.buildVlanConfig(c)
+	if l.VlanMode != "" && (usePut || hasDifference(changed, "vlan_mode", "vlan_tag", "vlan_trunks")) {
+		vlanConfig, err := l.buildVlanConfig(ctx, c, "Update Error")
 		if err != nil {
 			return err
 		}
@@ -230,26 +285,31 @@ func (l *LagInterface) Update(c *Client, usePut bool) error {
 		}
 	}
 
+	if !usePut {
+		updateMap = changedBody(updateMap, changed)
+	}
+
 	// Execute request
 	updateBody, _ := json.Marshal(updateMap)
 	jsonBody := bytes.NewBuffer(updateBody)
 
 	var res *http.Response
+	var err error
 	if usePut {
-		res = put(c, url, jsonBody)
+		res, err = put(ctx, c, url, jsonBody)
+		if err != nil {
+			return err
+		}
 		if res.Status != "200 OK" {
-			return &RequestError{
-				StatusCode: "PUT failed: " + res.Status,
-				Err:        errors.New("update error"),
-			}
+			return newAPIError("update error", res)
 		}
 	} else {
-		res = patch(c, url, jsonBody)
+		res, err = patch(ctx, c, url, jsonBody)
+		if err != nil {
+			return err
+		}
 		if res.Status != "204 No Content" {
-			return &RequestError{
-				StatusCode: "PATCH failed: " + res.Status,
-				Err:        errors.New("update error"),
-			}
+			return newAPIError("update error", res)
 		}
 	}
 
@@ -259,24 +319,34 @@ func (l *LagInterface) Update(c *Client, usePut bool) error {
 
 // Delete removes LAG Interface configuration
 func (l *LagInterface) Delete(c *Client) error {
+	return l.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (l *LagInterface) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", l.Kind(), l.Key())
+	if err := c.requireCapability(CapabilityLagInterfaces); err != nil {
+		return err
+	}
+
 	if l.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface Name",
-			Err:        errors.New("delete error"),
+			Err:        fmt.Errorf("Delete Error: %w", ErrValidation),
 		}
 	}
 
 	baseURI := "system/interfaces"
 	intStr := url.PathEscape(l.Name)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + baseURI + "/" + intStr
+	url := c.restURL() + "/" + baseURI + "/" + intStr
 
-	res := delete(c, url)
+	res, err := delete(ctx, c, url)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" && res.Status != "200 OK" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("delete error"),
-		}
+		return newAPIError("delete error", res)
 	}
 
 	l.materialized = false
@@ -285,82 +355,62 @@ func (l *LagInterface) Delete(c *Client) error {
 
 // Get retrieves LAG Interface configuration
 func (l *LagInterface) Get(c *Client) error {
+	return l.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (l *LagInterface) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityLagInterfaces, CapabilityWritableSelector); err != nil {
+		return err
+	}
+
 	if l.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Interface Name",
-			Err:        errors.New("get error"),
+			Err:        fmt.Errorf("Get Error: %w", ErrValidation),
 		}
 	}
 
 	baseURI := "system/interfaces"
 	intStr := url.PathEscape(l.Name)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + baseURI + "/" + intStr + "?selector=writable"
-
-	res, body := get(c, url)
+	url := c.restURL() + "/" + baseURI + "/" + intStr + "?selector=writable"
 
-	if res.Status != "200 OK" {
+	var attributes InterfaceAttributes
+	if err := getJSON(ctx, c, "get error", url, &attributes); err != nil {
 		l.materialized = false
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("get error"),
-		}
+		return err
 	}
 
-	// Initialize details map
-	if l.InterfaceDetails == nil {
-		l.InterfaceDetails = make(map[string]interface{})
-	}
+	l.loadDetails(attributes)
+	return nil
+}
 
-	// Parse response
-	for key, value := range body {
-		l.InterfaceDetails[key] = value
+// loadDetails populates the LAG Interface from the writable attributes returned by the switch.
+func (l *LagInterface) loadDetails(attributes InterfaceAttributes) {
+	l.InterfaceDetails = attributes
 
-		switch key {
-		case "description":
-			if value != nil {
-				l.Description = value.(string)
-			}
-		case "admin":
-			if value != nil {
-				l.AdminState = value.(string)
-			}
-		case "lacp":
-			if value != nil {
-				l.LacpMode = value.(string)
-			}
-		case "vlan_mode":
-			if value != nil {
-				l.VlanMode = value.(string)
-				l.NativeVlanTag = (l.VlanMode == "native-tagged")
-			}
-		case "vlan_tag":
-			if value != nil {
-				for vlanStr := range value.(map[string]interface{}) {
-					if vlanInt, err := strconv.Atoi(vlanStr); err == nil {
-						l.VlanTag = vlanInt
-					}
-				}
-			}
-		case "vlan_trunks":
-			vlanMap := value.(map[string]interface{})
-			if len(vlanMap) > 0 {
-				var vlanIds []interface{}
-				for vlanStr := range vlanMap {
-					if vlanInt, err := strconv.Atoi(vlanStr); err == nil {
-						vlanIds = append(vlanIds, vlanInt)
-					}
-				}
-				l.VlanIds = vlanIds
-				l.TrunkAllowedAll = false
-			} else {
-				l.TrunkAllowedAll = true
-				l.VlanIds = []interface{}{}
-			}
-		}
+	if attributes.Has("description") {
+		l.Description = attributes.Description
+	}
+	if attributes.Has("admin") {
+		l.AdminState = attributes.Admin
+	}
+	if attributes.Has("lacp") {
+		l.LacpMode = attributes.Lacp
+	}
+	if attributes.Has("vlan_mode") {
+		l.VlanMode = attributes.VlanMode
+		l.NativeVlanTag = (l.VlanMode == "native-tagged")
+	}
+	for _, vlanInt := range attributes.VlanTag.IntKeys() {
+		l.VlanTag = vlanInt
+	}
+	if attributes.Has("vlan_trunks") {
+		l.VlanIds = attributes.VlanTrunks.IntKeys()
+		l.TrunkAllowedAll = len(l.VlanIds) == 0
 	}
 
 	l.materialized = true
-	return nil
 }
 
 // GetStatus returns True if LAG Interface exists on Client object or False if not
@@ -368,7 +418,78 @@ func (l *LagInterface) GetStatus() bool {
 	return l.materialized
 }
 
+// Kind returns "LagInterface"
+func (l *LagInterface) Kind() string {
+	return "LagInterface"
+}
+
+// Key returns the name of the LAG, e.g. "lag60"
+func (l *LagInterface) Key() string {
+	return l.Name
+}
+
+// URI returns the path of the LAG Interface relative to the versioned REST API
+func (l *LagInterface) URI() string {
+	return interfaceURI(l.Name)
+}
+
+// Exists reports whether the LAG is configured on the switch
+func (l *LagInterface) Exists(c *Client) (bool, error) {
+	return l.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (l *LagInterface) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, l, CapabilityLagInterfaces)
+}
+
 // GetURI returns URI of LAG Interface
 func (l *LagInterface) GetURI() string {
 	return l.uri
 }
+
+// Ensure creates the LAG Interface, or updates it when its configuration differs from the switch
+func (l *LagInterface) Ensure(c *Client) (EnsureResult, error) {
+	return l.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (l *LagInterface) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, l)
+}
+
+// EnsureAbsent deletes the LAG Interface if it exists
+func (l *LagInterface) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return l.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (l *LagInterface) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureAbsent(ctx, c, l)
+}
+
+// state returns the attributes of the LAG Interface, see stateful; LacpMode
+// and the VLAN configuration are only managed when set
+func (l *LagInterface) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, l.Mask)
+	state.add("description", l.Description, l.Description != "")
+	state.add("admin", l.AdminState, l.AdminState != "")
+	state.add("lacp", l.LacpMode, l.LacpMode != "")
+
+	// A trunk is stored as native-untagged or native-tagged, see trunkVlanMode
+	vlanMode := l.VlanMode
+	if desired && vlanMode != "" && vlanMode != "access" {
+		vlanMode = trunkVlanMode(vlanMode, l.NativeVlanTag)
+	}
+	state.addVlanState(vlanMode, l.VlanTag, l.VlanIds, false, l.VlanMode != "")
+	return state.values
+}
+
+// writableState reads the writable attributes of the LAG from the switch
+func (l *LagInterface) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	current := LagInterface{Name: l.Name}
+	if err := current.GetContext(ctx, c); err != nil {
+		return nil, err
+	}
+	return current.state(false), nil
+}
@@ -0,0 +1,249 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestLagInterfaceCheckValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		lag     aoscxgo.LagInterface
+		wantErr bool
+	}{
+		{name: "valid", lag: aoscxgo.LagInterface{Name: "lag1", AdminState: "up"}},
+		{name: "active LACP", lag: aoscxgo.LagInterface{Name: "lag60", AdminState: "down", LacpMode: "active"}},
+		{name: "passive LACP", lag: aoscxgo.LagInterface{Name: "lag2", AdminState: "up", LacpMode: "passive"}},
+		{name: "invalid name", lag: aoscxgo.LagInterface{Name: "port-channel1", AdminState: "up"}, wantErr: true},
+		{name: "missing number", lag: aoscxgo.LagInterface{Name: "lag", AdminState: "up"}, wantErr: true},
+		{name: "invalid admin state", lag: aoscxgo.LagInterface{Name: "lag1", AdminState: "enabled"}, wantErr: true},
+		{name: "invalid LACP mode", lag: aoscxgo.LagInterface{Name: "lag1", AdminState: "up", LacpMode: "on"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lag.CheckValues()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, aoscxgo.ErrValidation) {
+				t.Errorf("checkValues() error = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestLagInterfaceCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		lag        aoscxgo.LagInterface
+		wantErr    error
+		wantMode   string
+		wantTag    []string
+		wantTrunks []string
+	}{
+		{
+			name:     "access defaults to VLAN 1",
+			lag:      aoscxgo.LagInterface{VlanMode: "access"},
+			wantMode: "access",
+			wantTag:  []string{"1"},
+		},
+		{
+			name: "no VLAN configuration",
+			lag:  aoscxgo.LagInterface{LacpMode: "active"},
+		},
+		{
+			name:       "trunk with native VLAN",
+			lag:        aoscxgo.LagInterface{VlanMode: "native-untagged", VlanTag: 100, VlanIds: []int{100, 200}},
+			wantMode:   "native-untagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"100", "200"},
+		},
+		{
+			name:       "trunk without native VLAN",
+			lag:        aoscxgo.LagInterface{VlanMode: "native-tagged", VlanIds: []int{200}},
+			wantMode:   "native-tagged",
+			wantTrunks: []string{"200"},
+		},
+		{
+			name:       "trunk maps to native-untagged",
+			lag:        aoscxgo.LagInterface{VlanMode: "trunk", VlanTag: 100, VlanIds: []int{100, 200}},
+			wantMode:   "native-untagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"100", "200"},
+		},
+		{
+			name:       "trunk with NativeVlanTag maps to native-tagged",
+			lag:        aoscxgo.LagInterface{VlanMode: "trunk", VlanTag: 100, NativeVlanTag: true, VlanIds: []int{200}},
+			wantMode:   "native-tagged",
+			wantTag:    []string{"100"},
+			wantTrunks: []string{"200"},
+		},
+		{
+			name:    "invalid VLAN mode",
+			lag:     aoscxgo.LagInterface{VlanMode: "hybrid"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "missing VLAN cannot be created",
+			lag:     aoscxgo.LagInterface{VlanMode: "access", VlanTag: 999},
+			wantErr: aoscxgo.ErrDependency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 100, 200)
+
+			tt.lag.Name = "lag10"
+			tt.lag.AdminState = "up"
+			tt.lag.Description = "to core"
+
+			err := tt.lag.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				var request_err *aoscxgo.RequestError
+				if !errors.As(err, &request_err) || !strings.HasPrefix(request_err.Err.Error(), "Create Error: ") {
+					t.Errorf("Create() error = %v, want a RequestError wrapping the sentinel as Create Error", err)
+				}
+				if _, exists := srv.Interface("lag10"); exists {
+					t.Error("LAG created despite error")
+				}
+				return
+			}
+
+			if !tt.lag.GetStatus() || tt.lag.GetURI() != "/rest/"+sw.Version+"/system/interfaces/lag10" {
+				t.Errorf("GetStatus() = %v, GetURI() = %q", tt.lag.GetStatus(), tt.lag.GetURI())
+			}
+
+			stored, ok := srv.Interface("lag10")
+			if !ok {
+				t.Fatal("LAG not stored on switch")
+			}
+			if stored["type"] != "lag" || stored["description"] != "to core" {
+				t.Errorf("stored LAG = %v", stored)
+			}
+			if tt.lag.LacpMode != "" && stored["lacp"] != tt.lag.LacpMode {
+				t.Errorf("lacp = %v, want %q", stored["lacp"], tt.lag.LacpMode)
+			}
+			if tt.wantMode != "" && stored["vlan_mode"] != tt.wantMode {
+				t.Errorf("vlan_mode = %v, want %q", stored["vlan_mode"], tt.wantMode)
+			}
+			if got := referenceKeys(stored["vlan_tag"]); len(got) != len(tt.wantTag) || (len(got) > 0 && !reflect.DeepEqual(got, tt.wantTag)) {
+				t.Errorf("vlan_tag = %v, want %v", stored["vlan_tag"], tt.wantTag)
+			}
+			if tt.wantTrunks != nil && !reflect.DeepEqual(referenceKeys(stored["vlan_trunks"]), tt.wantTrunks) {
+				t.Errorf("vlan_trunks = %v, want %v", stored["vlan_trunks"], tt.wantTrunks)
+			}
+		})
+	}
+}
+
+func TestLagInterfaceTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    aoscxgo.LagInterface
+		to      aoscxgo.LagInterface
+		usePut  bool
+		want    aoscxgo.LagInterface
+		wantIds []int
+	}{
+		{
+			name:    "access to trunk",
+			from:    aoscxgo.LagInterface{VlanMode: "access", VlanTag: 100},
+			to:      aoscxgo.LagInterface{VlanMode: "native-untagged", VlanTag: 100, VlanIds: []int{100, 200}},
+			want:    aoscxgo.LagInterface{VlanMode: "native-untagged", VlanTag: 100},
+			wantIds: []int{100, 200},
+		},
+		{
+			name:    "access to trunk given as trunk",
+			from:    aoscxgo.LagInterface{VlanMode: "access", VlanTag: 100},
+			to:      aoscxgo.LagInterface{VlanMode: "trunk", VlanTag: 100, NativeVlanTag: true, VlanIds: []int{100, 200}},
+			want:    aoscxgo.LagInterface{VlanMode: "native-tagged", VlanTag: 100, NativeVlanTag: true},
+			wantIds: []int{100, 200},
+		},
+		{
+			name: "trunk to access",
+			from: aoscxgo.LagInterface{VlanMode: "native-untagged", VlanTag: 100, VlanIds: []int{200}},
+			to:   aoscxgo.LagInterface{VlanMode: "access", VlanTag: 200},
+			want: aoscxgo.LagInterface{VlanMode: "access", VlanTag: 200},
+		},
+		{
+			name:    "trunk to all VLANs with PUT",
+			from:    aoscxgo.LagInterface{VlanMode: "native-tagged", VlanTag: 100, VlanIds: []int{200}},
+			to:      aoscxgo.LagInterface{VlanMode: "native-tagged", VlanTag: 100, LacpMode: "passive"},
+			usePut:  true,
+			want:    aoscxgo.LagInterface{VlanMode: "native-tagged", VlanTag: 100, NativeVlanTag: true, TrunkAllowedAll: true, LacpMode: "passive"},
+			wantIds: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			createVlans(t, sw, 100, 200)
+
+			tt.from.Name, tt.from.AdminState = "lag20", "up"
+			tt.to.Name, tt.to.AdminState = "lag20", "down"
+
+			if err := tt.from.Create(sw); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if err := update(sw, &tt.to, tt.usePut); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			got := aoscxgo.LagInterface{Name: "lag20"}
+			if err := got.Get(sw); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.VlanMode != tt.want.VlanMode || got.VlanTag != tt.want.VlanTag || got.AdminState != "down" ||
+				got.NativeVlanTag != tt.want.NativeVlanTag || got.TrunkAllowedAll != tt.want.TrunkAllowedAll {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+			if tt.want.LacpMode != "" && got.LacpMode != tt.want.LacpMode {
+				t.Errorf("LacpMode = %q, want %q", got.LacpMode, tt.want.LacpMode)
+			}
+			if tt.wantIds != nil && !reflect.DeepEqual(sortedInts(got.VlanIds), tt.wantIds) {
+				t.Errorf("VlanIds = %v, want %v", got.VlanIds, tt.wantIds)
+			}
+		})
+	}
+}
+
+func TestLagInterfaceDelete(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+
+	lag := aoscxgo.LagInterface{Name: "lag30", AdminState: "up"}
+	if err := lag.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := lag.Delete(sw); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if lag.GetStatus() {
+		t.Error("GetStatus() = true after Delete")
+	}
+	if _, exists := srv.Interface("lag30"); exists {
+		t.Error("LAG still exists after Delete")
+	}
+
+	if err := lag.Get(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := lag.Delete(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+
+	unnamed := aoscxgo.LagInterface{}
+	if err := unnamed.Delete(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Delete() without name error = %v, want ErrValidation", err)
+	}
+}
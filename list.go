@@ -0,0 +1,162 @@
+package aoscxgo
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListVlans returns every VLAN configured on the switch, ordered by VlanId.
+func ListVlans(c *Client) ([]Vlan, error) {
+	return ListVlansContext(context.Background(), c)
+}
+
+// ListVlansContext is like ListVlans but uses ctx for every request made to the switch.
+func ListVlansContext(ctx context.Context, c *Client) ([]Vlan, error) {
+	if err := c.requireCapability(CapabilityVlans); err != nil {
+		return nil, err
+	}
+
+	var collection map[string]VlanAttributes
+	if err := c.Get(ctx, "system/vlans", &QueryOptions{Depth: 1}, &collection); err != nil {
+		return nil, err
+	}
+
+	vlans := make([]Vlan, 0, len(collection))
+	for key, attributes := range collection {
+		vlan_id, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		vlan := Vlan{VlanId: vlan_id, uri: "/rest/" + c.Version + "/system/vlans/" + key}
+		vlan.loadDetails(attributes)
+		vlans = append(vlans, vlan)
+	}
+
+	sort.Slice(vlans, func(i, j int) bool { return vlans[i].VlanId < vlans[j].VlanId })
+	return vlans, nil
+}
+
+// ListInterfaces returns the interfaces of the switch whose attributes match
+// filter, ordered by name. A nil filter returns every interface, while e.g.
+// {"type": "system"} returns the physical ports only.
+func ListInterfaces(c *Client, filter map[string]string) ([]Interface, error) {
+	return ListInterfacesContext(context.Background(), c, filter)
+}
+
+// ListInterfacesContext is like ListInterfaces but uses ctx for every request made to the switch.
+func ListInterfacesContext(ctx context.Context, c *Client, filter map[string]string) ([]Interface, error) {
+	if err := c.requireCapability(CapabilityInterfaces); err != nil {
+		return nil, err
+	}
+
+	collection, err := listInterfaces(ctx, c, &QueryOptions{Depth: 1, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make([]Interface, 0, len(collection))
+	for _, name := range sortedKeys(collection) {
+		iface := Interface{Name: name, uri: "/rest/" + c.Version + "/system/interfaces/" + url.PathEscape(name)}
+		iface.loadDetails(collection[name])
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces, nil
+}
+
+// ListLagInterfaces returns every LAG Interface of the switch, ordered by name.
+func ListLagInterfaces(c *Client) ([]LagInterface, error) {
+	return ListLagInterfacesContext(context.Background(), c)
+}
+
+// ListLagInterfacesContext is like ListLagInterfaces but uses ctx for every request made to the switch.
+func ListLagInterfacesContext(ctx context.Context, c *Client) ([]LagInterface, error) {
+	if err := c.requireCapability(CapabilityLagInterfaces, CapabilityWritableSelector); err != nil {
+		return nil, err
+	}
+
+	collection, err := listInterfaces(ctx, c, &QueryOptions{
+		Depth:    1,
+		Selector: SelectorWritable,
+		Filter:   map[string]string{"type": "lag"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make([]LagInterface, 0, len(collection))
+	for _, name := range sortedKeys(collection) {
+		lag := LagInterface{Name: name, uri: "/rest/" + c.Version + "/system/interfaces/" + name}
+		lag.loadDetails(collection[name])
+		lags = append(lags, lag)
+	}
+	return lags, nil
+}
+
+// ListVlanInterfaces returns every VlanInterface of the switch, ordered by VLAN.
+// The IPv6 addresses of each interface are read with one additional request.
+func ListVlanInterfaces(c *Client) ([]VlanInterface, error) {
+	return ListVlanInterfacesContext(context.Background(), c)
+}
+
+// ListVlanInterfacesContext is like ListVlanInterfaces but uses ctx for every request made to the switch.
+func ListVlanInterfacesContext(ctx context.Context, c *Client) ([]VlanInterface, error) {
+	if err := c.requireCapability(CapabilityVlanInterfaces, CapabilityIP6Addresses, CapabilityWritableSelector); err != nil {
+		return nil, err
+	}
+
+	collection, err := listInterfaces(ctx, c, &QueryOptions{
+		Depth:    1,
+		Selector: SelectorWritable,
+		Filter:   map[string]string{"type": "vlan"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vlan_interfaces := make([]VlanInterface, 0, len(collection))
+	for name, attributes := range collection {
+		vlan_id, err := strconv.Atoi(strings.TrimPrefix(name, "vlan"))
+		if err != nil || !strings.HasPrefix(name, "vlan") {
+			continue
+		}
+
+		vlan_interface := VlanInterface{Vlan: Vlan{VlanId: vlan_id}}
+		vlan_interface.loadDetails(attributes)
+
+		var ip6_addresses map[string]interface{}
+		if err := c.Get(ctx, "system/interfaces/"+name+"/ip6_addresses", nil, &ip6_addresses); err != nil {
+			return nil, err
+		}
+		vlan_interface.loadIP6Addresses(ip6_addresses)
+		vlan_interface.materialized = true
+
+		vlan_interfaces = append(vlan_interfaces, vlan_interface)
+	}
+
+	sort.Slice(vlan_interfaces, func(i, j int) bool {
+		return vlan_interfaces[i].Vlan.VlanId < vlan_interfaces[j].Vlan.VlanId
+	})
+	return vlan_interfaces, nil
+}
+
+// listInterfaces reads the system/interfaces collection, keyed by interface name.
+func listInterfaces(ctx context.Context, c *Client, opts *QueryOptions) (map[string]InterfaceAttributes, error) {
+	var collection map[string]InterfaceAttributes
+	if err := c.Get(ctx, "system/interfaces", opts, &collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// sortedKeys returns the keys of a collection in ascending order.
+func sortedKeys(collection map[string]InterfaceAttributes) []string {
+	keys := make([]string, 0, len(collection))
+	for key := range collection {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
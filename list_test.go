@@ -0,0 +1,184 @@
+package aoscxgo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// newListSwitch returns a fake switch with VLANs, LAGs and VLAN interfaces configured.
+func newListSwitch(t *testing.T) *aoscxgo.Client {
+	t.Helper()
+	_, sw := newTestSwitch(t)
+	createVlans(t, sw, 300, 20, 100)
+
+	lags := []aoscxgo.LagInterface{
+		{Name: "lag2", AdminState: "up", Description: "access", VlanMode: "access", VlanTag: 20},
+		{Name: "lag1", AdminState: "down", Description: "uplink", VlanMode: "trunk", VlanIds: []int{20, 100}, VlanTag: 300},
+	}
+	for _, lag := range lags {
+		if err := lag.Create(sw); err != nil {
+			t.Fatalf("create %s: %v", lag.Name, err)
+		}
+	}
+
+	vlan_interfaces := []aoscxgo.VlanInterface{
+		{Vlan: aoscxgo.Vlan{VlanId: 100, AdminState: "up"}, Description: "servers", Ipv4: []string{"10.100.0.1/24"}, Ipv6: []string{"2001:db8:100::1/64", "2001:db8:100::2/64"}},
+		{Vlan: aoscxgo.Vlan{VlanId: 20, AdminState: "up"}, Description: "clients", Ipv4: []string{"10.20.0.1/24"}},
+	}
+	for _, vi := range vlan_interfaces {
+		if err := vi.Create(sw); err != nil {
+			t.Fatalf("create vlan%d: %v", vi.Vlan.VlanId, err)
+		}
+	}
+
+	port := aoscxgo.Interface{Name: "1/1/3", AdminState: "up", Description: "printer"}
+	if err := port.Update(sw); err != nil {
+		t.Fatal(err)
+	}
+	return sw
+}
+
+func TestListVlans(t *testing.T) {
+	sw := newListSwitch(t)
+
+	vlans, err := aoscxgo.ListVlans(sw)
+	if err != nil {
+		t.Fatalf("ListVlans() error = %v", err)
+	}
+
+	var ids []int
+	for _, vlan := range vlans {
+		ids = append(ids, vlan.VlanId)
+
+		want := aoscxgo.Vlan{VlanId: vlan.VlanId}
+		if err := want.Get(sw); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(vlan, want) {
+			t.Errorf("listed VLAN %d = %+v, Get() = %+v", vlan.VlanId, vlan, want)
+		}
+	}
+	if !reflect.DeepEqual(ids, []int{1, 20, 100, 300}) {
+		t.Errorf("ListVlans() ids = %v", ids)
+	}
+}
+
+func TestListInterfaces(t *testing.T) {
+	sw := newListSwitch(t)
+
+	tests := []struct {
+		name   string
+		filter map[string]string
+		want   []string
+	}{
+		{
+			name:   "physical ports",
+			filter: map[string]string{"type": "system"},
+			want:   []string{"1/1/1", "1/1/2", "1/1/3", "1/1/4", "1/1/5", "1/1/6", "1/1/7", "1/1/8"},
+		},
+		{
+			name:   "LAGs",
+			filter: map[string]string{"type": "lag"},
+			want:   []string{"lag1", "lag2"},
+		},
+		{
+			name:   "administratively up",
+			filter: map[string]string{"admin": "up"},
+			want:   []string{"1/1/3", "lag2", "vlan100", "vlan20"},
+		},
+		{
+			name:   "no match",
+			filter: map[string]string{"type": "loopback"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interfaces, err := aoscxgo.ListInterfaces(sw, tt.filter)
+			if err != nil {
+				t.Fatalf("ListInterfaces() error = %v", err)
+			}
+			var names []string
+			for _, iface := range interfaces {
+				names = append(names, iface.Name)
+				if !iface.GetStatus() || iface.InterfaceDetails.Type == "" {
+					t.Errorf("%s was not populated: %+v", iface.Name, iface)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("ListInterfaces() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	all, err := aoscxgo.ListInterfaces(sw, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 12 {
+		t.Errorf("ListInterfaces(nil) returned %d interfaces, want 12", len(all))
+	}
+	for _, iface := range all {
+		if iface.Name == "1/1/3" && (iface.Description != "printer" || iface.AdminState != "up") {
+			t.Errorf("1/1/3 = %+v", iface)
+		}
+	}
+}
+
+func TestListLagInterfaces(t *testing.T) {
+	sw := newListSwitch(t)
+
+	lags, err := aoscxgo.ListLagInterfaces(sw)
+	if err != nil {
+		t.Fatalf("ListLagInterfaces() error = %v", err)
+	}
+	if len(lags) != 2 || lags[0].Name != "lag1" || lags[1].Name != "lag2" {
+		t.Fatalf("ListLagInterfaces() = %+v", lags)
+	}
+
+	for _, lag := range lags {
+		want := aoscxgo.LagInterface{Name: lag.Name}
+		if err := want.Get(sw); err != nil {
+			t.Fatal(err)
+		}
+		if lag.Description != want.Description || lag.AdminState != want.AdminState ||
+			lag.VlanMode != want.VlanMode || lag.VlanTag != want.VlanTag ||
+			!reflect.DeepEqual(sortedInts(lag.VlanIds), sortedInts(want.VlanIds)) ||
+			!reflect.DeepEqual(lag.InterfaceDetails, want.InterfaceDetails) || !lag.GetStatus() {
+			t.Errorf("listed %s = %+v, Get() = %+v", lag.Name, lag, want)
+		}
+	}
+	if got := sortedInts(lags[0].VlanIds); !reflect.DeepEqual(got, []int{20, 100}) {
+		t.Errorf("lag1 VlanIds = %v", got)
+	}
+}
+
+func TestListVlanInterfaces(t *testing.T) {
+	sw := newListSwitch(t)
+
+	vlan_interfaces, err := aoscxgo.ListVlanInterfaces(sw)
+	if err != nil {
+		t.Fatalf("ListVlanInterfaces() error = %v", err)
+	}
+	if len(vlan_interfaces) != 2 || vlan_interfaces[0].Vlan.VlanId != 20 || vlan_interfaces[1].Vlan.VlanId != 100 {
+		t.Fatalf("ListVlanInterfaces() = %+v", vlan_interfaces)
+	}
+
+	for _, vi := range vlan_interfaces {
+		want := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: vi.Vlan.VlanId}}
+		if err := want.Get(sw); err != nil {
+			t.Fatal(err)
+		}
+		if vi.Description != want.Description || vi.Vrf != want.Vrf ||
+			!reflect.DeepEqual(vi.Ipv4, want.Ipv4) ||
+			!reflect.DeepEqual(sortedStrings(vi.Ipv6), sortedStrings(want.Ipv6)) || !vi.GetStatus() {
+			t.Errorf("listed vlan%d = %+v, Get() = %+v", vi.Vlan.VlanId, vi, want)
+		}
+	}
+
+	if ipv6 := sortedStrings(vlan_interfaces[1].Ipv6); !reflect.DeepEqual(ipv6, []string{"2001:db8:100::1/64", "2001:db8:100::2/64"}) {
+		t.Errorf("vlan100 Ipv6 = %v", ipv6)
+	}
+}
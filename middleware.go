@@ -0,0 +1,38 @@
+package aoscxgo
+
+import (
+	"net/http"
+)
+
+// RoundTripperFunc adapts an ordinary function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the round trip of every REST call made by resource operations.
+// A middleware sees the method, URL, headers and body of each request and the
+// response returned by next, and may modify either of them. Request bodies can
+// be read without consuming them through req.GetBody.
+//
+// Example adding a header to every request:
+//
+//	sw.Middleware = append(sw.Middleware, func(next http.RoundTripper) http.RoundTripper {
+//		return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//			req.Header.Set("X-Forwarded-User", "automation")
+//			return next.RoundTrip(req)
+//		})
+//	})
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// transport returns the client transport wrapped by its middleware chain.
+// The first middleware in the list is the outermost one.
+func (c *Client) transport() http.RoundTripper {
+	var rt http.RoundTripper = c.Transport
+	for index := len(c.Middleware) - 1; index >= 0; index-- {
+		rt = c.Middleware[index](rt)
+	}
+	return rt
+}
@@ -0,0 +1,150 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// callRecorder records the order in which middleware sees requests.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *callRecorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *callRecorder) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// recording returns a middleware recording name and the request path before
+// and after passing the request on.
+func recording(recorder *callRecorder, name string) aoscxgo.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorder.add(name + " before " + req.URL.Path)
+			res, err := next.RoundTrip(req)
+			recorder.add(name + " after " + req.URL.Path)
+			return res, err
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	recorder := &callRecorder{}
+	client := srv.Client()
+	client.Middleware = []aoscxgo.Middleware{recording(recorder, "outer"), recording(recorder, "inner")}
+	sw, err := aoscxgo.Connect(client)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	// The request after expiry logs in again
+	srv.ExpireSessions()
+	vlan := aoscxgo.Vlan{VlanId: 1}
+	if err := vlan.Get(sw); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := sw.Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	var vlan_calls []string
+	for _, call := range recorder.Calls() {
+		if strings.HasSuffix(call, "/login") || strings.HasSuffix(call, "/logout") {
+			t.Errorf("middleware saw %q, want login and logout excluded", call)
+		}
+		if strings.HasSuffix(call, "/system/vlans/1") {
+			vlan_calls = append(vlan_calls, strings.Fields(call)[0]+" "+strings.Fields(call)[1])
+		}
+	}
+	if logins(srv) != 2 {
+		t.Errorf("logins = %d, want 2", logins(srv))
+	}
+	// The first attempt is rejected with the expired session
+	want := []string{"outer before", "inner before", "inner after", "outer after", "outer before", "inner before", "inner after", "outer after"}
+	if strings.Join(vlan_calls, ", ") != strings.Join(want, ", ") {
+		t.Errorf("middleware calls = %v, want %v", vlan_calls, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked by policy")
+
+	tests := []struct {
+		name    string
+		respond func(req *http.Request) (*http.Response, error)
+		check   func(t *testing.T, vlan aoscxgo.Vlan, err error)
+	}{
+		{
+			name: "response",
+			respond: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Status:     http.StatusText(http.StatusOK),
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"id": 1, "name": "cached", "admin": "up"}`)),
+					Request:    req,
+				}, nil
+			},
+			check: func(t *testing.T, vlan aoscxgo.Vlan, err error) {
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if vlan.Name != "cached" {
+					t.Errorf("Name = %q, want the middleware response", vlan.Name)
+				}
+			},
+		},
+		{
+			name: "error",
+			respond: func(req *http.Request) (*http.Response, error) {
+				return nil, errBlocked
+			},
+			check: func(t *testing.T, vlan aoscxgo.Vlan, err error) {
+				if !errors.Is(err, errBlocked) {
+					t.Errorf("Get() error = %v, want %v", err, errBlocked)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			recorder := &callRecorder{}
+			sw.Middleware = []aoscxgo.Middleware{
+				func(next http.RoundTripper) http.RoundTripper {
+					return aoscxgo.RoundTripperFunc(tt.respond)
+				},
+				recording(recorder, "inner"),
+			}
+			srv.ResetRequests()
+
+			vlan := aoscxgo.Vlan{VlanId: 1}
+			err := vlan.Get(sw)
+			tt.check(t, vlan, err)
+			if calls := recorder.Calls(); len(calls) != 0 {
+				t.Errorf("inner middleware saw %v, want the request stopped", calls)
+			}
+			if requests := srv.Requests(); len(requests) != 0 {
+				t.Errorf("switch received %d requests, want none", len(requests))
+			}
+		})
+	}
+}
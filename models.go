@@ -0,0 +1,206 @@
+package aoscxgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// References is a reference attribute such as vlan_tag or vrf, mapping the key
+// of each referenced row to its URI, e.g. {"100": "/rest/v10.09/system/vlans/100"}.
+type References map[string]string
+
+// Keys returns the keys of the referenced rows in ascending order.
+func (r References) Keys() []string {
+	keys := make([]string, 0, len(r))
+	for key := range r {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IntKeys returns the numeric keys of the referenced rows, such as VLAN ids,
+// in ascending order. Keys that are not numbers are skipped.
+func (r References) IntKeys() []int {
+	ids := make([]int, 0, len(r))
+	for key := range r {
+		if id, err := strconv.Atoi(key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// VlanAttributes is a row of the system/vlans table as returned by the switch.
+// Attributes this package does not model are kept in Extra, so that decoding
+// and encoding a VLAN returns the attributes it was decoded from.
+type VlanAttributes struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Description     string `json:"description"`
+	Admin           string `json:"admin"`
+	OperState       string `json:"oper_state"`
+	OperStateReason string `json:"oper_state_reason"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+	// present records the attributes found when decoding, false for null.
+	present map[string]bool
+}
+
+// Has reports whether the switch returned the named attribute, even if null.
+func (a *VlanAttributes) Has(name string) bool {
+	_, ok := a.present[name]
+	return ok
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *VlanAttributes) UnmarshalJSON(data []byte) error {
+	return unmarshalAttributes(data, a, &a.present, &a.Extra)
+}
+
+// MarshalJSON implements json.Marshaler
+func (a VlanAttributes) MarshalJSON() ([]byte, error) {
+	return marshalAttributes(&a, a.present, a.Extra)
+}
+
+// InterfaceAttributes is a row of the system/interfaces table as returned by
+// the switch, covering physical ports, LAGs and VLAN interfaces. Attributes
+// this package does not model are kept in Extra.
+type InterfaceAttributes struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Description string            `json:"description"`
+	Admin       string            `json:"admin"`
+	UserConfig  map[string]string `json:"user_config"`
+	AdminState  string            `json:"admin_state"`
+	LinkState   string            `json:"link_state"`
+	MTU         int               `json:"mtu"`
+
+	Routing    bool       `json:"routing"`
+	VlanMode   string     `json:"vlan_mode"`
+	VlanTag    References `json:"vlan_tag"`
+	VlanTrunks References `json:"vlan_trunks"`
+
+	Vrf                 References `json:"vrf"`
+	Ip4Address          string     `json:"ip4_address"`
+	Ip4AddressSecondary []string   `json:"ip4_address_secondary"`
+	Ip6Addresses        References `json:"ip6_addresses"`
+
+	// Interfaces lists the members of a LAG, or the VLAN of a VLAN interface.
+	Interfaces References `json:"interfaces"`
+	Lacp       string     `json:"lacp"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+	// present records the attributes found when decoding, false for null.
+	present map[string]bool
+}
+
+// Has reports whether the switch returned the named attribute, even if null.
+func (a *InterfaceAttributes) Has(name string) bool {
+	_, ok := a.present[name]
+	return ok
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *InterfaceAttributes) UnmarshalJSON(data []byte) error {
+	return unmarshalAttributes(data, a, &a.present, &a.Extra)
+}
+
+// MarshalJSON implements json.Marshaler
+func (a InterfaceAttributes) MarshalJSON() ([]byte, error) {
+	return marshalAttributes(&a, a.present, a.Extra)
+}
+
+// values returns the attributes as a map, as they would be sent to the switch.
+func (a InterfaceAttributes) values() map[string]interface{} {
+	encoded, _ := json.Marshal(a)
+	values := map[string]interface{}{}
+	json.Unmarshal(encoded, &values)
+	return values
+}
+
+// count returns the number of attributes found when decoding.
+func (a *InterfaceAttributes) count() int {
+	return len(a.present) + len(a.Extra)
+}
+
+// unmarshalAttributes decodes a JSON object into the tagged fields of model,
+// recording which attributes were present and keeping the others in extra.
+// A value of the wrong type is an error rather than a zero value.
+func unmarshalAttributes(data []byte, model interface{}, present *map[string]bool, extra *map[string]json.RawMessage) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(model).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	*present = map[string]bool{}
+	fields := attributeFields(value.Type())
+
+	for name, message := range raw {
+		index, known := fields[name]
+		if !known {
+			if *extra == nil {
+				*extra = map[string]json.RawMessage{}
+			}
+			(*extra)[name] = message
+			continue
+		}
+
+		is_null := string(message) == "null"
+		(*present)[name] = !is_null
+		if is_null {
+			continue
+		}
+		if err := json.Unmarshal(message, value.Field(index).Addr().Interface()); err != nil {
+			return fmt.Errorf("attribute %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// marshalAttributes encodes the tagged fields of model that are set or were
+// present when decoding, together with the extra attributes.
+func marshalAttributes(model interface{}, present map[string]bool, extra map[string]json.RawMessage) ([]byte, error) {
+	value := reflect.ValueOf(model).Elem()
+	attributes := make(map[string]json.RawMessage, len(extra)+len(present))
+	for name, message := range extra {
+		attributes[name] = message
+	}
+
+	for name, index := range attributeFields(value.Type()) {
+		field := value.Field(index)
+		not_null, was_present := present[name]
+		switch {
+		case !field.IsZero() || (was_present && not_null):
+			encoded, err := json.Marshal(field.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			attributes[name] = encoded
+		case was_present:
+			attributes[name] = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(attributes)
+}
+
+// attributeFields maps the JSON attribute names of a model to its field indices.
+func attributeFields(model reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for index := 0; index < model.NumField(); index++ {
+		field := model.Field(index)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.IsExported() && name != "" && name != "-" {
+			fields[name] = index
+		}
+	}
+	return fields
+}
@@ -0,0 +1,158 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// jsonEqual reports whether two JSON documents hold the same value.
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var value_a, value_b interface{}
+	if err := json.Unmarshal(a, &value_a); err != nil {
+		t.Fatalf("%s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &value_b); err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+	return reflect.DeepEqual(value_a, value_b)
+}
+
+func TestAttributesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		input string
+	}{
+		{
+			name:  "VLAN",
+			model: &aoscxgo.VlanAttributes{},
+			input: `{"id": 100, "name": "uplink", "type": "static", "admin": "up", "description": "",
+				"oper_state": "down", "voice": false, "flood_enabled_subsystems": ["vrrp"], "vsx_sync": null}`,
+		},
+		{
+			name:  "VLAN with null description",
+			model: &aoscxgo.VlanAttributes{},
+			input: `{"id": 1, "name": "DEFAULT_VLAN_1", "description": null}`,
+		},
+		{
+			name:  "access port",
+			model: &aoscxgo.InterfaceAttributes{},
+			input: `{"name": "1/1/1", "type": "system", "admin": "up", "description": "printer",
+				"user_config": {"admin": "up"}, "routing": false, "vlan_mode": "access",
+				"vlan_tag": {"20": "/rest/v10.09/system/vlans/20"}, "vlan_trunks": {},
+				"mtu": 1500, "l3_counters_enable": {"rx": true}, "vrf": null}`,
+		},
+		{
+			name:  "LAG trunk",
+			model: &aoscxgo.InterfaceAttributes{},
+			input: `{"name": "lag1", "type": "lag", "lacp": "active", "vlan_mode": "native-untagged",
+				"vlan_trunks": {"20": "/rest/v10.09/system/vlans/20", "100": "/rest/v10.09/system/vlans/100"},
+				"interfaces": {"1/1/1": "/rest/v10.09/system/interfaces/1%2F1%2F1"}, "other_config": {"lacp-time": "fast"}}`,
+		},
+		{
+			name:  "VLAN interface",
+			model: &aoscxgo.InterfaceAttributes{},
+			input: `{"name": "vlan100", "type": "vlan", "ip4_address": "10.0.0.1/24",
+				"ip4_address_secondary": ["10.0.1.1/24"], "vrf": {"default": "/rest/v10.09/system/vrfs/default"},
+				"ip6_addresses": {}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.input), tt.model); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			encoded, err := json.Marshal(tt.model)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !jsonEqual(t, encoded, []byte(tt.input)) {
+				t.Errorf("round trip = %s, want %s", encoded, tt.input)
+			}
+		})
+	}
+}
+
+func TestInterfaceAttributes(t *testing.T) {
+	var attributes aoscxgo.InterfaceAttributes
+	input := `{"name": "lag1", "description": "", "vlan_trunks": {"200": "/rest/v10.09/system/vlans/200",
+		"20": "/rest/v10.09/system/vlans/20"}, "vrf": null, "other_config": {"lacp-time": "fast"}}`
+	if err := json.Unmarshal([]byte(input), &attributes); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := attributes.VlanTrunks.IntKeys(); !reflect.DeepEqual(got, []int{20, 200}) {
+		t.Errorf("VlanTrunks.IntKeys() = %v", got)
+	}
+	if got := attributes.VlanTrunks.Keys(); !reflect.DeepEqual(got, []string{"20", "200"}) {
+		t.Errorf("VlanTrunks.Keys() = %v", got)
+	}
+	for _, name := range []string{"name", "description", "vlan_trunks", "vrf"} {
+		if !attributes.Has(name) {
+			t.Errorf("Has(%q) = false", name)
+		}
+	}
+	if attributes.Has("vlan_tag") || attributes.Has("other_config") {
+		t.Error("Has() reports attributes that were not decoded as known fields")
+	}
+	if got := string(attributes.Extra["other_config"]); got != `{"lacp-time": "fast"}` {
+		t.Errorf("Extra[other_config] = %s", got)
+	}
+
+	// Changes to known fields are encoded, unset fields are left out
+	attributes.Description = "uplink"
+	attributes.Admin = "up"
+	encoded, _ := json.Marshal(attributes)
+	want := `{"name": "lag1", "description": "uplink", "admin": "up", "vrf": null, "other_config": {"lacp-time": "fast"},
+		"vlan_trunks": {"200": "/rest/v10.09/system/vlans/200", "20": "/rest/v10.09/system/vlans/20"}}`
+	if !jsonEqual(t, encoded, []byte(want)) {
+		t.Errorf("Marshal() = %s, want %s", encoded, want)
+	}
+
+	// Decoding replaces the previous contents
+	if err := json.Unmarshal([]byte(`{"name": "lag2"}`), &attributes); err != nil {
+		t.Fatal(err)
+	}
+	if attributes.Description != "" || attributes.Extra != nil || attributes.Has("vrf") {
+		t.Errorf("decoding again kept previous attributes: %+v", attributes)
+	}
+
+	if err := json.Unmarshal([]byte(`{"vlan_trunks": ["/rest/v10.09/system/vlans/20"]}`), &attributes); err == nil {
+		t.Error("Unmarshal() of a list into vlan_trunks succeeded")
+	}
+}
+
+func TestGetUnexpectedType(t *testing.T) {
+	_, sw := newTestSwitch(t)
+	ctx := context.Background()
+	createVlans(t, sw, 100)
+
+	// A description of the wrong type is reported instead of panicking
+	if err := sw.Patch(ctx, "system/vlans/100", map[string]interface{}{"description": 42}); err != nil {
+		t.Fatal(err)
+	}
+	vlan := aoscxgo.Vlan{VlanId: 100}
+	if err := vlan.Get(sw); err == nil {
+		t.Fatal("Get() error = nil for a numeric description")
+	}
+	if vlan.GetStatus() {
+		t.Error("GetStatus() = true after a failed Get()")
+	}
+
+	lag := aoscxgo.LagInterface{Name: "lag1", AdminState: "up"}
+	if err := lag.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Patch(ctx, "system/interfaces/lag1", map[string]interface{}{"admin": true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lag.Get(sw); err == nil {
+		t.Fatal("Get() error = nil for a boolean admin")
+	}
+}
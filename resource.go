@@ -0,0 +1,62 @@
+package aoscxgo
+
+import (
+	"context"
+	"errors"
+)
+
+// Resource is the method set shared by the configuration types of the package,
+// so that reconcilers, exporters and bulk executors can work over a []Resource.
+// Vlan, Interface, L2Interface, L3Interface, LagInterface and VlanInterface
+// implement it.
+type Resource interface {
+	// Kind returns the name of the type, e.g. "Vlan".
+	Kind() string
+	// Key returns the key of the object in its table, e.g. "100" for VLAN 100
+	// or "1/1/1" for a port.
+	Key() string
+	// URI returns the path of the object relative to the versioned REST API,
+	// with the key escaped, as accepted by Client.Get.
+	URI() string
+
+	Create(c *Client) error
+	CreateContext(ctx context.Context, c *Client) error
+	Get(c *Client) error
+	GetContext(ctx context.Context, c *Client) error
+	// Update changes the attributes set on the object with PATCH.
+	Update(c *Client) error
+	UpdateContext(ctx context.Context, c *Client) error
+	Delete(c *Client) error
+	DeleteContext(ctx context.Context, c *Client) error
+	// Exists reports whether the object is present on the switch, without
+	// changing the receiver.
+	Exists(c *Client) (bool, error)
+	ExistsContext(ctx context.Context, c *Client) (bool, error)
+	// Ensure creates the object, or updates it when its attributes differ.
+	Ensure(c *Client) (EnsureResult, error)
+	EnsureContext(ctx context.Context, c *Client) (EnsureResult, error)
+	// EnsureAbsent deletes the object if it is present.
+	EnsureAbsent(c *Client) (EnsureResult, error)
+	EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error)
+}
+
+var (
+	_ Resource = (*Vlan)(nil)
+	_ Resource = (*Interface)(nil)
+	_ Resource = (*L2Interface)(nil)
+	_ Resource = (*L3Interface)(nil)
+	_ Resource = (*LagInterface)(nil)
+	_ Resource = (*VlanInterface)(nil)
+)
+
+// exists performs GET on the URI of r and reports whether the switch found it.
+func exists(ctx context.Context, c *Client, r Resource, capabilities ...Capability) (bool, error) {
+	if err := c.requireCapability(capabilities...); err != nil {
+		return false, err
+	}
+	err := c.Get(ctx, r.URI(), &QueryOptions{Attributes: []string{"name"}}, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
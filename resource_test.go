@@ -0,0 +1,63 @@
+package aoscxgo_test
+
+import (
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestResources(t *testing.T) {
+	_, sw := newTestSwitch(t)
+	createVlans(t, sw, 100)
+
+	port := aoscxgo.Interface{Name: "1/1/3", AdminState: "up"}
+	tests := []struct {
+		resource aoscxgo.Resource
+		kind     string
+		key      string
+		uri      string
+		// existing resources are present on the switch before Create, and
+		// remaining ones are still present after Delete defaulted them
+		existing  bool
+		remaining bool
+	}{
+		{&aoscxgo.Vlan{VlanId: 200, Name: "servers"}, "Vlan", "200", "system/vlans/200", false, false},
+		{&aoscxgo.Interface{Name: "1/1/9", AdminState: "up"}, "Interface", "1/1/9", "system/interfaces/1%2F1%2F9", false, true},
+		{&aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/2", AdminState: "up"}, VlanTag: 100}, "L2Interface", "1/1/2", "system/interfaces/1%2F1%2F2", true, true},
+		{&aoscxgo.L3Interface{Interface: port, Ipv4: []string{"10.0.3.1/24"}}, "L3Interface", "1/1/3", "system/interfaces/1%2F1%2F3", true, true},
+		{&aoscxgo.LagInterface{Name: "lag10", AdminState: "up"}, "LagInterface", "lag10", "system/interfaces/lag10", false, false},
+		{&aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}, Ipv4: []string{"10.0.100.1/24"}}, "VlanInterface", "vlan100", "system/interfaces/vlan100", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			r := tt.resource
+			if r.Kind() != tt.kind || r.Key() != tt.key || r.URI() != tt.uri {
+				t.Errorf("Kind(), Key(), URI() = %q, %q, %q", r.Kind(), r.Key(), r.URI())
+			}
+
+			found, err := r.Exists(sw)
+			if err != nil || found != tt.existing {
+				t.Fatalf("Exists() before Create = %v, %v, want %v", found, err, tt.existing)
+			}
+			if err := r.Create(sw); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if found, err := r.Exists(sw); err != nil || !found {
+				t.Fatalf("Exists() after Create = %v, %v", found, err)
+			}
+			if err := r.Update(sw); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if err := r.Get(sw); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if err := r.Delete(sw); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if found, err := r.Exists(sw); err != nil || found != tt.remaining {
+				t.Errorf("Exists() after Delete = %v, %v, want %v", found, err, tt.remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package aoscxgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Selector restricts a GET to one category of attributes.
+type Selector string
+
+// Selectors accepted by the AOS-CX REST API.
+const (
+	SelectorConfiguration Selector = "configuration"
+	SelectorStatus        Selector = "status"
+	SelectorStatistics    Selector = "statistics"
+	// SelectorWritable returns the attributes that can be sent back with PUT.
+	SelectorWritable Selector = "writable"
+)
+
+// QueryOptions are the query parameters of a GET request. The zero value
+// requests the switch defaults.
+type QueryOptions struct {
+	// Depth expands references in the response, e.g. 1 returns the objects of
+	// a collection instead of their URIs.
+	Depth int
+	// Attributes limits the response to the named attributes.
+	Attributes []string
+	Selector   Selector
+	// Filter keeps the objects of a collection whose attributes have the given
+	// values, e.g. {"type": "lag"}.
+	Filter map[string]string
+}
+
+// Values encodes the options as URL query parameters.
+func (o *QueryOptions) Values() url.Values {
+	values := url.Values{}
+	if o == nil {
+		return values
+	}
+	if o.Depth > 0 {
+		values.Set("depth", strconv.Itoa(o.Depth))
+	}
+	if len(o.Attributes) > 0 {
+		values.Set("attributes", strings.Join(o.Attributes, ","))
+	}
+	if o.Selector != "" {
+		values.Set("selector", string(o.Selector))
+	}
+	if len(o.Filter) > 0 {
+		filters := make([]string, 0, len(o.Filter))
+		for attribute, value := range o.Filter {
+			filters = append(filters, attribute+":"+value)
+		}
+		sort.Strings(filters)
+		values.Set("filter", strings.Join(filters, ","))
+	}
+	return values
+}
+
+// resourceURL returns the URL of path, which is either relative to the
+// versioned REST API, e.g. "system/vlans/100", or a URI returned by the
+// switch, e.g. "/rest/v10.09/system/vlans/100". Keys in path must already be
+// escaped with url.PathEscape.
+func (c *Client) resourceURL(path string, opts *QueryOptions) string {
+	var resource_url string
+	if strings.HasPrefix(path, "/rest/") {
+		resource_url = strings.TrimSuffix(c.baseURL(), "/rest") + path
+	} else {
+		resource_url = c.restURL() + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	if query := opts.Values().Encode(); query != "" {
+		resource_url += "?" + query
+	}
+	return resource_url
+}
+
+// Get performs GET on path and decodes the JSON response into out, which may be
+// nil to only check that the object exists. See resourceURL for the format of
+// path. A missing object returns an APIError matching ErrNotFound.
+func (c *Client) Get(ctx context.Context, path string, opts *QueryOptions, out interface{}) error {
+	return getJSON(ctx, c, "Retrieval Error", c.resourceURL(path, opts), out)
+}
+
+// getJSON performs GET to the given URL and decodes the JSON response into out.
+// Any status other than 200 is returned as an APIError for op.
+func getJSON(ctx context.Context, client *Client, op string, url string, out interface{}) error {
+	req, err := setupRequest(ctx, client, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := executeRequest(client, req)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return newAPIError(op, res)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// Post performs POST on the collection at path with body encoded as JSON.
+func (c *Client) Post(ctx context.Context, path string, body interface{}) error {
+	json_body, err := encodeBody(body)
+	if err != nil {
+		return err
+	}
+	res, err := post(ctx, c, c.resourceURL(path, nil), json_body)
+	if err != nil {
+		return err
+	}
+	return checkResponse("Create Error", res)
+}
+
+// Put performs PUT on path with body encoded as JSON, replacing every writable
+// attribute of the object.
+func (c *Client) Put(ctx context.Context, path string, body interface{}) error {
+	json_body, err := encodeBody(body)
+	if err != nil {
+		return err
+	}
+	res, err := put(ctx, c, c.resourceURL(path, nil), json_body)
+	if err != nil {
+		return err
+	}
+	return checkResponse("Update Error", res)
+}
+
+// Patch performs PATCH on path with body encoded as JSON, changing only the
+// attributes present in body.
+func (c *Client) Patch(ctx context.Context, path string, body interface{}) error {
+	json_body, err := encodeBody(body)
+	if err != nil {
+		return err
+	}
+	res, err := patch(ctx, c, c.resourceURL(path, nil), json_body)
+	if err != nil {
+		return err
+	}
+	return checkResponse("Update Error", res)
+}
+
+// Delete performs DELETE on path.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	res, err := delete(ctx, c, c.resourceURL(path, nil))
+	if err != nil {
+		return err
+	}
+	return checkResponse("Delete Error", res)
+}
+
+// encodeBody encodes body as JSON. Bodies that are already encoded, such as
+// json.RawMessage, are sent as they are.
+func encodeBody(body interface{}) (*bytes.Buffer, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request body: %w", err)
+	}
+	return bytes.NewBuffer(encoded), nil
+}
+
+// checkResponse returns an APIError for any status other than 2xx and closes the body.
+func checkResponse(op string, res *http.Response) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newAPIError(op, res)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return nil
+}
@@ -0,0 +1,189 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestQueryOptionsValues(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *aoscxgo.QueryOptions
+		want string
+	}{
+		{"nil", nil, ""},
+		{"zero", &aoscxgo.QueryOptions{}, ""},
+		{"depth", &aoscxgo.QueryOptions{Depth: 2}, "depth=2"},
+		{"attributes", &aoscxgo.QueryOptions{Attributes: []string{"name", "admin"}}, "attributes=name%2Cadmin"},
+		{"selector", &aoscxgo.QueryOptions{Selector: aoscxgo.SelectorWritable}, "selector=writable"},
+		{
+			"filter",
+			&aoscxgo.QueryOptions{Filter: map[string]string{"type": "lag", "admin": "up"}},
+			"filter=admin%3Aup%2Ctype%3Alag",
+		},
+		{
+			"combined",
+			&aoscxgo.QueryOptions{Depth: 1, Selector: aoscxgo.SelectorStatus, Filter: map[string]string{"type": "vlan"}},
+			"depth=1&filter=type%3Avlan&selector=status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Values().Encode(); got != tt.want {
+				t.Errorf("Values() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *aoscxgo.Client
+		want   string
+	}{
+		{"defaults", &aoscxgo.Client{Hostname: "10.0.0.1"}, "https://10.0.0.1/rest/v10.09"},
+		{"http scheme", &aoscxgo.Client{Hostname: "10.0.0.1", Scheme: "http"}, "http://10.0.0.1/rest/v10.09"},
+		{"http scheme and port", &aoscxgo.Client{Hostname: "10.0.0.1", Scheme: "http", Port: 8080}, "http://10.0.0.1:8080/rest/v10.09"},
+		{"IPv6 hostname and port", &aoscxgo.Client{Hostname: "[2001:db8::1]", Port: 8443}, "https://[2001:db8::1]:8443/rest/v10.09"},
+		{"BasePath", &aoscxgo.Client{Hostname: "proxy", BasePath: "switches/core1"}, "https://proxy/switches/core1/rest/v10.09"},
+		{"BasePath with leading slash", &aoscxgo.Client{Hostname: "proxy", BasePath: "/switches/core1"}, "https://proxy/switches/core1/rest/v10.09"},
+		{"BasePath with trailing slash", &aoscxgo.Client{Hostname: "proxy", BasePath: "switches/core1/"}, "https://proxy/switches/core1/rest/v10.09"},
+		{"BasePath with both slashes", &aoscxgo.Client{Hostname: "proxy", BasePath: "/switches/core1/"}, "https://proxy/switches/core1/rest/v10.09"},
+		{"BasePath of a slash", &aoscxgo.Client{Hostname: "10.0.0.1", BasePath: "/"}, "https://10.0.0.1/rest/v10.09"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.client.Version = "v10.09"
+			if got := tt.client.RestURL(); got != tt.want {
+				t.Errorf("restURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		basePath string
+		path     string
+		opts     *aoscxgo.QueryOptions
+		want     string
+	}{
+		{"relative path", "", "system/vlans/100", nil, "https://10.0.0.1/rest/v10.09/system/vlans/100"},
+		{"relative path with leading slash", "", "/system/vlans/100", nil, "https://10.0.0.1/rest/v10.09/system/vlans/100"},
+		{"prefixed path", "", "/rest/v10.09/system/vlans/100", nil, "https://10.0.0.1/rest/v10.09/system/vlans/100"},
+		{"prefixed path of another version", "", "/rest/v10.04/system/vlans/100", nil, "https://10.0.0.1/rest/v10.04/system/vlans/100"},
+		{"relative path under BasePath", "/switches/core1/", "system/vlans/100", nil, "https://10.0.0.1/switches/core1/rest/v10.09/system/vlans/100"},
+		{"prefixed path under BasePath", "/switches/core1/", "/rest/v10.09/system/vlans/100", nil, "https://10.0.0.1/switches/core1/rest/v10.09/system/vlans/100"},
+		{"escaped key", "", "system/interfaces/1%2F1%2F1", nil, "https://10.0.0.1/rest/v10.09/system/interfaces/1%2F1%2F1"},
+		{"query", "switches/core1", "system/vlans", &aoscxgo.QueryOptions{Depth: 1}, "https://10.0.0.1/switches/core1/rest/v10.09/system/vlans?depth=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := aoscxgo.Client{Hostname: "10.0.0.1", Version: "v10.09", BasePath: tt.basePath}
+			if got := client.ResourceURL(tt.path, tt.opts); got != tt.want {
+				t.Errorf("resourceURL(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	_, sw := newTestSwitch(t)
+	ctx := context.Background()
+	createVlans(t, sw, 100, 200)
+
+	type vlan struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	var got vlan
+	if err := sw.Get(ctx, "system/vlans/100", nil, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ID != 100 || got.Name != "VLAN100" {
+		t.Errorf("Get() = %+v", got)
+	}
+
+	// URIs returned by the switch can be followed as they are
+	var references map[string]string
+	if err := sw.Get(ctx, "system/vlans", nil, &references); err != nil {
+		t.Fatal(err)
+	}
+	var followed vlan
+	if err := sw.Get(ctx, references["200"], nil, &followed); err != nil || followed.ID != 200 {
+		t.Errorf("Get(%q) = %+v, %v", references["200"], followed, err)
+	}
+
+	var writable map[string]interface{}
+	if err := sw.Get(ctx, "system/vlans/100", &aoscxgo.QueryOptions{Selector: aoscxgo.SelectorWritable}, &writable); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := writable["id"]; ok {
+		t.Errorf("writable selector returned read-only id: %v", writable)
+	}
+
+	var vlans map[string]vlan
+	opts := &aoscxgo.QueryOptions{Depth: 1, Attributes: []string{"id", "name"}, Filter: map[string]string{"name": "VLAN200"}}
+	if err := sw.Get(ctx, "system/vlans", opts, &vlans); err != nil {
+		t.Fatal(err)
+	}
+	if len(vlans) != 1 || vlans["200"].ID != 200 {
+		t.Errorf("filtered collection = %+v", vlans)
+	}
+
+	if err := sw.Get(ctx, "system/vlans/300", nil, nil); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Get() of a missing VLAN error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientMutations(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	ctx := context.Background()
+
+	if err := sw.Post(ctx, "system/vlans", map[string]interface{}{"id": 100, "name": "uplink"}); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if err := sw.Post(ctx, "system/vlans", map[string]interface{}{"id": 100, "name": "uplink"}); !errors.Is(err, aoscxgo.ErrConflict) {
+		t.Errorf("Post() of a duplicate error = %v, want ErrConflict", err)
+	}
+
+	if err := sw.Patch(ctx, "system/vlans/100", json.RawMessage(`{"description":"patched"}`)); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if vlan, _ := srv.Vlan(100); vlan["description"] != "patched" || vlan["name"] != "uplink" {
+		t.Errorf("after Patch() VLAN 100 = %v", vlan)
+	}
+
+	if err := sw.Put(ctx, "system/vlans/100", map[string]interface{}{"name": "replaced"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if vlan, _ := srv.Vlan(100); vlan["description"] != nil || vlan["name"] != "replaced" {
+		t.Errorf("after Put() VLAN 100 = %v", vlan)
+	}
+
+	if err := sw.Patch(ctx, "system/interfaces/"+url.PathEscape("1/1/1"), map[string]string{"description": "uplink"}); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := srv.Interface("1/1/1"); port["description"] != "uplink" {
+		t.Errorf("after Patch() 1/1/1 = %v", port)
+	}
+
+	if err := sw.Delete(ctx, "system/vlans/100"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := srv.Vlan(100); ok {
+		t.Error("VLAN 100 still exists after Delete()")
+	}
+}
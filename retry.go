@@ -0,0 +1,124 @@
+package aoscxgo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults used for unset RetryPolicy fields.
+const (
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy configures how requests that fail with a transient error, such as a
+// connection reset or a 429, 502, 503 or 504 response, are retried. Backoff grows
+// exponentially from InitialBackoff up to MaxBackoff with random jitter, and a
+// Retry-After header sent by the switch takes precedence.
+//
+// GET, PUT and DELETE are idempotent and retried automatically. POST and PATCH
+// are only retried when RetryPost and RetryPatch are set, since repeating them
+// after a partial failure may apply a change twice. A request whose session
+// expired and could not be re-established is never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	RetryPost      bool
+	RetryPatch     bool
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts that retries idempotent requests only.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+	}
+}
+
+// shouldRetry reports whether the given attempt of req, which produced res or err, should be retried.
+func (p *RetryPolicy) shouldRetry(req *http.Request, res *http.Response, err error, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts || !p.retriesMethod(req.Method) {
+		return false
+	}
+	if err != nil {
+		return transientError(err)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// transientError reports whether a request that failed with err may succeed
+// when sent again. Cancellation by the caller and failed logins are permanent.
+func transientError(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, errReauthentication), errors.Is(err, ErrUnauthorized), errors.Is(err, ErrSessionLimit):
+		return false
+	}
+	return true
+}
+
+// retriesMethod reports whether requests with the given method may be retried.
+func (p *RetryPolicy) retriesMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryPost
+	case http.MethodPatch:
+		return p.RetryPatch
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given attempt.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	max_backoff := p.MaxBackoff
+	if max_backoff <= 0 {
+		max_backoff = defaultMaxBackoff
+	}
+
+	if res != nil {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return min(delay, max_backoff)
+		}
+	}
+
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = defaultInitialBackoff
+	}
+	for i := 1; i < attempt && delay < max_backoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max_backoff)
+
+	// Jitter between half and the full delay spreads out retries from concurrent callers
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,244 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+// failing answers the first failures requests of method with status and
+// retry_after, counting every request of method in attempts.
+func failing(method string, failures int, status int, retry_after string, attempts *int32) aoscxgo.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != method {
+				return next.RoundTrip(req)
+			}
+			if int(atomic.AddInt32(attempts, 1)) > failures {
+				return next.RoundTrip(req)
+			}
+			header := http.Header{}
+			if retry_after != "" {
+				header.Set("Retry-After", retry_after)
+			}
+			return &http.Response{
+				Status:     http.StatusText(status),
+				StatusCode: status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	fast := func(policy aoscxgo.RetryPolicy) *aoscxgo.RetryPolicy {
+		policy.InitialBackoff = time.Millisecond
+		policy.MaxBackoff = 2 * time.Millisecond
+		return &policy
+	}
+
+	tests := []struct {
+		name         string
+		policy       *aoscxgo.RetryPolicy
+		method       string
+		failures     int
+		status       int
+		retryAfter   string
+		wantAttempts int32
+		wantErr      bool
+		maxElapsed   time.Duration
+		minElapsed   time.Duration
+	}{
+		{name: "GET recovers", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodGet, failures: 2, status: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "GET bounded by MaxAttempts", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodGet, failures: 5, status: http.StatusServiceUnavailable, wantAttempts: 3, wantErr: true},
+		{name: "GET with nil policy", method: http.MethodGet, failures: 1, status: http.StatusServiceUnavailable, wantAttempts: 1, wantErr: true},
+		{name: "GET not retried on 400", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodGet, failures: 1, status: http.StatusBadRequest, wantAttempts: 1, wantErr: true},
+		{name: "GET retried on 429", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodGet, failures: 1, status: http.StatusTooManyRequests, wantAttempts: 2},
+		{name: "PUT retried by default", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodPut, failures: 1, status: http.StatusBadGateway, wantAttempts: 2},
+		{name: "DELETE retried by default", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodDelete, failures: 1, status: http.StatusGatewayTimeout, wantAttempts: 2},
+		{name: "POST not retried by default", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodPost, failures: 1, status: http.StatusServiceUnavailable, wantAttempts: 1, wantErr: true},
+		{name: "POST with RetryPost", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3, RetryPost: true}), method: http.MethodPost, failures: 1, status: http.StatusServiceUnavailable, wantAttempts: 2},
+		{name: "PATCH not retried by default", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3}), method: http.MethodPatch, failures: 1, status: http.StatusServiceUnavailable, wantAttempts: 1, wantErr: true},
+		{name: "PATCH with RetryPatch", policy: fast(aoscxgo.RetryPolicy{MaxAttempts: 3, RetryPatch: true}), method: http.MethodPatch, failures: 1, status: http.StatusServiceUnavailable, wantAttempts: 2},
+		{
+			name:   "Retry-After of zero seconds overrides the backoff",
+			policy: &aoscxgo.RetryPolicy{MaxAttempts: 2, InitialBackoff: 5 * time.Second, MaxBackoff: 5 * time.Second},
+			method: http.MethodGet, failures: 1, status: http.StatusServiceUnavailable, retryAfter: "0",
+			wantAttempts: 2, maxElapsed: time.Second,
+		},
+		{
+			name:   "Retry-After in seconds",
+			policy: &aoscxgo.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Second},
+			method: http.MethodGet, failures: 1, status: http.StatusServiceUnavailable, retryAfter: "1",
+			wantAttempts: 2, minElapsed: time.Second, maxElapsed: 3 * time.Second,
+		},
+		{
+			name:   "Retry-After as an HTTP date in the past",
+			policy: &aoscxgo.RetryPolicy{MaxAttempts: 2, InitialBackoff: 5 * time.Second, MaxBackoff: 5 * time.Second},
+			method: http.MethodGet, failures: 1, status: http.StatusServiceUnavailable, retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			wantAttempts: 2, maxElapsed: time.Second,
+		},
+		{
+			name:   "Retry-After capped by MaxBackoff",
+			policy: &aoscxgo.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+			method: http.MethodGet, failures: 1, status: http.StatusServiceUnavailable, retryAfter: "120",
+			wantAttempts: 2, maxElapsed: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			createVlans(t, sw, 100)
+
+			var attempts int32
+			sw.RetryPolicy = tt.policy
+			sw.Middleware = append(sw.Middleware, failing(tt.method, tt.failures, tt.status, tt.retryAfter, &attempts))
+
+			ctx := context.Background()
+			start := time.Now()
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				err = sw.Get(ctx, "system/vlans/100", nil, nil)
+			case http.MethodPost:
+				err = sw.Post(ctx, "system/vlans", map[string]interface{}{"id": 200, "name": "retried", "type": "static"})
+			case http.MethodPut:
+				err = sw.Put(ctx, "system/vlans/100", map[string]interface{}{"name": "replaced"})
+			case http.MethodPatch:
+				err = sw.Patch(ctx, "system/vlans/100", map[string]interface{}{"description": "patched"})
+			case http.MethodDelete:
+				err = sw.Delete(ctx, "system/vlans/100")
+			}
+			elapsed := time.Since(start)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.maxElapsed > 0 && elapsed > tt.maxElapsed {
+				t.Errorf("took %v, want at most %v", elapsed, tt.maxElapsed)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %v, want at least %v", elapsed, tt.minElapsed)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	_, sw := newTestSwitch(t)
+
+	var attempts int32
+	sw.RetryPolicy = &aoscxgo.RetryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Second, MaxBackoff: 10 * time.Second}
+	sw.Middleware = append(sw.Middleware, failing(http.MethodGet, 5, http.StatusServiceUnavailable, "", &attempts))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := sw.Get(ctx, "system/vlans/1", nil, nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v, want the backoff to stop at the deadline", elapsed)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestRetryStopsOnFailedLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{"unauthorized", http.StatusUnauthorized, aoscxgo.ErrUnauthorized},
+		{"session limit", http.StatusTooManyRequests, aoscxgo.ErrSessionLimit},
+		{"no session", http.StatusOK, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			sw.RetryPolicy = &aoscxgo.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+			var logins int32
+			transport := sw.Transport
+			sw.Transport = aoscxgo.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if !strings.HasSuffix(req.URL.Path, "/login") {
+					return transport.RoundTrip(req)
+				}
+				atomic.AddInt32(&logins, 1)
+				return &http.Response{
+					Status:     http.StatusText(tt.status),
+					StatusCode: tt.status,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			})
+			srv.ExpireSessions()
+			srv.ResetRequests()
+
+			err := sw.Get(context.Background(), "system/vlans/1", nil, nil)
+			if err == nil || !strings.Contains(err.Error(), "re-authentication failed") {
+				t.Fatalf("Get() error = %v, want re-authentication to fail", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&logins); got != 1 {
+				t.Errorf("logins = %d, want 1", got)
+			}
+			gets := 0
+			for _, req := range srv.Requests() {
+				if req.Method == http.MethodGet && strings.HasSuffix(req.Path, "/system/vlans/1") {
+					gets++
+				}
+			}
+			if gets != 1 {
+				t.Errorf("GET attempts = %d, want 1", gets)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: "0", want: 0, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "soon", wantOK: false},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := aoscxgo.ParseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := aoscxgo.ParseRetryAfter(future); !ok || got < 58*time.Second || got > time.Minute {
+		t.Errorf("ParseRetryAfter(%q) = %v, %v, want about a minute", future, got, ok)
+	}
+}
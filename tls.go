@@ -0,0 +1,97 @@
+package aoscxgo
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrCertificatePin is returned when the switch certificate does not match any of
+// the configured PinnedFingerprints.
+var ErrCertificatePin = errors.New("certificate fingerprint does not match pinned fingerprints")
+
+// buildTLSConfig creates the TLS configuration used by the default transport from
+// the Client TLS options. Configuring a CA enables certificate verification, and
+// pinned fingerprints are checked in addition to, or instead of, chain verification.
+func (c *Client) buildTLSConfig() (*tls.Config, error) {
+	tls_config := &tls.Config{
+		InsecureSkipVerify: !c.VerifyCertificate,
+		ServerName:         c.ServerName,
+	}
+
+	if c.RootCAs != nil || c.CAFile != "" {
+		pool := c.RootCAs
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if c.CAFile != "" {
+			pem, err := os.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+			}
+		}
+		tls_config.RootCAs = pool
+		tls_config.InsecureSkipVerify = false
+	}
+
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tls_config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(c.PinnedFingerprints) > 0 {
+		pins := make(map[string]bool, len(c.PinnedFingerprints))
+		for _, fingerprint := range c.PinnedFingerprints {
+			normalized, err := normalizeFingerprint(fingerprint)
+			if err != nil {
+				return nil, err
+			}
+			pins[normalized] = true
+		}
+		tls_config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return ErrCertificatePin
+			}
+			sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+			if !pins[hex.EncodeToString(sum[:])] {
+				return fmt.Errorf("%w: %s", ErrCertificatePin, CertificateFingerprint(state.PeerCertificates[0]))
+			}
+			return nil
+		}
+	}
+
+	return tls_config, nil
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of cert in the
+// colon separated hex form accepted by PinnedFingerprints.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for index, b := range sum {
+		parts[index] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// normalizeFingerprint converts a SHA-256 fingerprint in hex, with or without
+// colons and in any case, to lowercase hex without separators.
+func normalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fingerprint))
+	decoded, err := hex.DecodeString(normalized)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,210 @@
+package aoscxgo_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// writePEM writes a PEM block of the given type to a file in dir.
+func writePEM(t *testing.T, dir, name, block_type string, der []byte) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: block_type, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// clientCertificate creates a self-signed client certificate and returns the
+// files holding it and its key.
+func clientCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "automation"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key_der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", key_der)
+}
+
+func TestTLSOptions(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	server_cert := srv.Certificate()
+	ca_file := writePEM(t, dir, "ca.pem", "CERTIFICATE", server_cert.Raw)
+	empty_file := filepath.Join(dir, "empty.pem")
+	os.WriteFile(empty_file, nil, 0o600)
+	pool := x509.NewCertPool()
+	pool.AddCert(server_cert)
+	other_pin := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name      string
+		configure func(c *aoscxgo.Client)
+		wantErr   error
+		wantText  string
+	}{
+		{
+			name:      "skip verification by default",
+			configure: func(c *aoscxgo.Client) {},
+		},
+		{
+			name:      "verification without a CA",
+			configure: func(c *aoscxgo.Client) { c.VerifyCertificate = true },
+			wantText:  "certificate",
+		},
+		{
+			name:      "RootCAs",
+			configure: func(c *aoscxgo.Client) { c.RootCAs = pool },
+		},
+		{
+			name:      "CAFile",
+			configure: func(c *aoscxgo.Client) { c.CAFile = ca_file },
+		},
+		{
+			name:      "missing CAFile",
+			configure: func(c *aoscxgo.Client) { c.CAFile = filepath.Join(dir, "missing.pem") },
+			wantText:  "unable to read CA file",
+		},
+		{
+			name:      "CAFile without certificates",
+			configure: func(c *aoscxgo.Client) { c.CAFile = empty_file },
+			wantText:  "no certificates found",
+		},
+		{
+			name: "ServerName matching the certificate",
+			configure: func(c *aoscxgo.Client) {
+				c.RootCAs = pool
+				c.ServerName = "example.com"
+			},
+		},
+		{
+			name: "ServerName not matching the certificate",
+			configure: func(c *aoscxgo.Client) {
+				c.RootCAs = pool
+				c.ServerName = "switch.example.net"
+			},
+			wantText: "switch.example.net",
+		},
+		{
+			name:      "pinned fingerprint",
+			configure: func(c *aoscxgo.Client) { c.PinnedFingerprints = []string{aoscxgo.CertificateFingerprint(server_cert)} },
+		},
+		{
+			name: "pinned fingerprint in lowercase without colons",
+			configure: func(c *aoscxgo.Client) {
+				fingerprint := strings.ToLower(strings.ReplaceAll(aoscxgo.CertificateFingerprint(server_cert), ":", ""))
+				c.PinnedFingerprints = []string{other_pin, fingerprint}
+			},
+		},
+		{
+			name:      "pinned fingerprint mismatch",
+			configure: func(c *aoscxgo.Client) { c.PinnedFingerprints = []string{other_pin} },
+			wantErr:   aoscxgo.ErrCertificatePin,
+		},
+		{
+			name: "pinned fingerprint mismatch with a trusted CA",
+			configure: func(c *aoscxgo.Client) {
+				c.RootCAs = pool
+				c.PinnedFingerprints = []string{other_pin}
+			},
+			wantErr: aoscxgo.ErrCertificatePin,
+		},
+		{
+			name:      "invalid fingerprint",
+			configure: func(c *aoscxgo.Client) { c.PinnedFingerprints = []string{"not hex"} },
+			wantText:  "invalid SHA-256 fingerprint",
+		},
+		{
+			name: "missing client certificate",
+			configure: func(c *aoscxgo.Client) {
+				c.ClientCertFile = filepath.Join(dir, "missing.pem")
+				c.ClientKeyFile = filepath.Join(dir, "missing.key")
+			},
+			wantText: "unable to load client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := srv.Client()
+			client.Transport = nil
+			tt.configure(client)
+
+			_, err := aoscxgo.Connect(client)
+			switch {
+			case tt.wantErr == nil && tt.wantText == "":
+				if err != nil {
+					t.Fatalf("Connect() error = %v", err)
+				}
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("Connect() error = %v, want %v", err, tt.wantErr)
+			case tt.wantText != "" && (err == nil || !strings.Contains(err.Error(), tt.wantText)):
+				t.Fatalf("Connect() error = %v, want it to mention %q", err, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	srv := aoscxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	var presented []string
+	srv.TLS.ClientAuth = tls.RequireAnyClientCert
+	srv.TLS.VerifyPeerCertificate = func(raw [][]byte, _ [][]*x509.Certificate) error {
+		cert, err := x509.ParseCertificate(raw[0])
+		if err != nil {
+			return err
+		}
+		presented = append(presented, cert.Subject.CommonName)
+		return nil
+	}
+
+	client := srv.Client()
+	client.Transport = nil
+	if _, err := aoscxgo.Connect(client); err == nil {
+		t.Fatal("Connect() without a client certificate succeeded")
+	}
+
+	client = srv.Client()
+	client.Transport = nil
+	client.ClientCertFile, client.ClientKeyFile = clientCertificate(t, t.TempDir())
+	if _, err := aoscxgo.Connect(client); err != nil {
+		t.Fatalf("Connect() with a client certificate error = %v", err)
+	}
+	if len(presented) == 0 || presented[0] != "automation" {
+		t.Errorf("client certificates presented = %v, want automation", presented)
+	}
+}
@@ -2,111 +2,232 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strings"
+	"time"
 )
 
-// RequestError represents a custom error for HTTP requests
-type RequestError struct {
-	StatusCode string
-	Err        error
-}
+// sleepContext pauses for d or until ctx is done, whichever happens first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-// Error implements the error interface
-func (r *RequestError) Error() string {
-	return fmt.Sprintf("Status: %s, Error: %v", r.StatusCode, r.Err)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // setupRequest creates and configures a basic HTTP request with common headers
-func setupRequest(client *Client, method, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
+func setupRequest(ctx context.Context, client *Client, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if err := client.requireRequestCapabilities(req); err != nil {
+		return nil, err
+	}
+
 	req.Header.Set("Accept", "*/*")
-	req.Header.Set("x-csrf-token", client.Csrf)
 	req.Close = false
-	req.AddCookie(client.Cookie)
+	setSessionHeaders(client, req)
 
 	return req, nil
 }
 
-// executeRequest performs the HTTP request and handles common errors
-func executeRequest(client *Client, req *http.Request) *http.Response {
-	res, err := client.Transport.RoundTrip(req)
+// setSessionHeaders attaches the current session cookie and CSRF token to req
+func setSessionHeaders(client *Client, req *http.Request) {
+	cookie, csrf := client.Session()
+	req.Header.Set("x-csrf-token", csrf)
+	req.Header.Del("Cookie")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+}
+
+// executeRequest performs the HTTP request and handles common errors.
+// Transient failures are retried according to the client RetryPolicy.
+func executeRequest(client *Client, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := sendRequest(client, req)
+		if !client.RetryPolicy.shouldRetry(req, res, err, attempt) {
+			return res, err
+		}
+
+		delay := client.RetryPolicy.backoff(attempt, res)
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		client.logger().Info("retrying request",
+			"method", req.Method, "path", req.URL.Path, "attempt", attempt, "delay", delay)
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if req, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendRequest performs a single attempt of the HTTP request.
+// If the switch reports that the session expired, the client logs in again
+// and the request is replayed once with the new session.
+func sendRequest(client *Client, req *http.Request) (*http.Response, error) {
+	res, err := roundTrip(client, req)
+	if err != nil || !sessionExpired(res) {
+		return res, err
+	}
+
+	// The body of the first attempt has been consumed, so the replay needs a fresh copy
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return res, nil
+	}
+
+	if err := client.reauthenticate(req.Context(), req.Header.Get("x-csrf-token")); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	res.Body.Close()
+
+	setSessionHeaders(client, retry)
+	return roundTrip(client, retry)
+}
+
+// cloneRequest returns a copy of req with a fresh body that can be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil, fmt.Errorf("%s %s cannot be replayed: request body is not rewindable", req.Method, req.URL.Redacted())
+	}
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// roundTrip sends req once over the client transport, waiting for a free
+// request slot when MaxConcurrentRequests is set
+func roundTrip(client *Client, req *http.Request) (*http.Response, error) {
+	if err := client.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	defer client.release()
+
+	start := time.Now()
+	res, err := client.transport().RoundTrip(req)
+	latency := time.Since(start)
 	if err != nil {
-		log.Fatalf("An Error Occurred %v", err)
+		client.logger().Warn("request failed",
+			"method", req.Method, "path", req.URL.Path, "latency", latency, "error", err)
+		return nil, fmt.Errorf("%s %s failed: %w", req.Method, req.URL.Redacted(), err)
 	}
-	return res
+	if res == nil {
+		return nil, fmt.Errorf("%s %s returned nil response", req.Method, req.URL.Redacted())
+	}
+	client.logger().Debug("request completed",
+		"method", req.Method, "path", req.URL.Path, "status", res.StatusCode, "latency", latency)
+	return res, nil
+}
+
+// sessionExpired reports whether res indicates that the session cookie is no longer valid.
+// AOS-CX answers 401 for an expired cookie, and 403 with a session message on some firmware.
+func sessionExpired(res *http.Response) bool {
+	if res.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if res.StatusCode != http.StatusForbidden || res.Body == nil {
+		return false
+	}
+
+	// Peek at the body and put it back for the caller
+	bodyBytes, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	return strings.Contains(strings.ToLower(string(bodyBytes)), "session")
 }
 
 // delete performs DELETE to the given URL and returns the response
-func delete(client *Client, url string) *http.Response {
-	req, err := setupRequest(client, "DELETE", url, nil)
+func delete(ctx context.Context, client *Client, url string) (*http.Response, error) {
+	req, err := setupRequest(ctx, client, "DELETE", url, nil)
 	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "text/plain")
 
-	return executeRequest(client, req)
+	return executeMutation(client, req)
 }
 
 // get performs GET to the given URL and returns the response and parsed JSON body
-func get(client *Client, url string) (*http.Response, map[string]interface{}) {
-	req, err := setupRequest(client, "GET", url, nil)
+func get(ctx context.Context, client *Client, url string) (*http.Response, map[string]interface{}, error) {
+	req, err := setupRequest(ctx, client, "GET", url, nil)
 	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+		return nil, nil, err
 	}
 	req.Header.Set("Content-Type", "text/plain")
 
-	res := executeRequest(client, req)
+	res, err := executeRequest(client, req)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	body := make(map[string]interface{})
 
 	// Check if response is a server error (5xx) - these are unexpected
 	if res.StatusCode >= 500 {
-		log.Printf("HTTP Server Error %d: %s", res.StatusCode, res.Status)
-		return res, body
+		client.logger().Warn("server error", "method", req.Method, "path", req.URL.Path, "status", res.StatusCode)
+		return res, body, nil
 	}
 
 	// For client errors (4xx), just return empty body - these are often expected (like 404 for existence checks)
 	if res.StatusCode >= 400 {
-		return res, body
+		return res, body, nil
 	}
 
 	// Read the response body first
 	bodyBytes, err := io.ReadAll(res.Body)
 	res.Body.Close()
 	if err != nil {
-		log.Printf("Failed to read response body: %v", err)
-		return res, body
+		return res, body, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check if the response looks like JSON
 	bodyStr := string(bodyBytes)
 	if len(bodyStr) == 0 {
-		return res, body
+		return res, body, nil
 	}
 
 	// Check content type to see if it's JSON
 	contentType := res.Header.Get("Content-Type")
 	if contentType != "" && !contains(contentType, "application/json") && !contains(contentType, "text/json") {
-		log.Printf("Warning: Response content-type is '%s', not JSON\nResponse body: %s", contentType, bodyStr)
-		return res, body
+		client.logger().Warn("response is not JSON",
+			"path", req.URL.Path, "content_type", contentType, "length", len(bodyBytes))
+		return res, body, nil
 	}
 
 	// Try to decode JSON from the bytes
 	if err := json.Unmarshal(bodyBytes, &body); err != nil {
-		log.Printf("Failed to decode JSON response: %v\nResponse body: %s", err, bodyStr)
+		client.logger().Warn("failed to decode JSON response",
+			"path", req.URL.Path, "length", len(bodyBytes), "error", err)
 		// Return empty body map instead of failing
-		return res, make(map[string]interface{})
+		return res, make(map[string]interface{}), nil
 	}
 
-	return res, body
+	return res, body, nil
 }
 
 // contains checks if a string contains a substring (case-insensitive helper)
@@ -129,49 +250,45 @@ func indexOf(s, substr string) int {
 }
 
 // getAcceptText performs GET to the given URL with text/plain Accept header
-func getAcceptText(client *Client, url string) (*http.Response, error) {
-	req, err := setupRequest(client, "GET", url, nil)
+func getAcceptText(ctx context.Context, client *Client, url string) (*http.Response, error) {
+	req, err := setupRequest(ctx, client, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "text/plain")
 
-	res, err := client.Transport.RoundTrip(req)
-	if err != nil {
-		return nil, err
-	}
-	return res, nil
+	return executeRequest(client, req)
 }
 
 // post performs POST to the given URL with the provided body and returns the response
-func post(client *Client, url string, jsonBody *bytes.Buffer) *http.Response {
-	req, err := setupRequest(client, "POST", url, jsonBody)
+func post(ctx context.Context, client *Client, url string, jsonBody *bytes.Buffer) (*http.Response, error) {
+	req, err := setupRequest(ctx, client, "POST", url, jsonBody)
 	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return executeRequest(client, req)
+	return executeMutation(client, req)
 }
 
 // put performs PUT to the given URL with the provided body and returns the response
-func put(client *Client, url string, jsonBody *bytes.Buffer) *http.Response {
-	req, err := setupRequest(client, "PUT", url, jsonBody)
+func put(ctx context.Context, client *Client, url string, jsonBody *bytes.Buffer) (*http.Response, error) {
+	req, err := setupRequest(ctx, client, "PUT", url, jsonBody)
 	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return executeRequest(client, req)
+	return executeMutation(client, req)
 }
 
 // patch performs PATCH to the given URL with the provided body and returns the response
-func patch(client *Client, url string, jsonBody *bytes.Buffer) *http.Response {
-	req, err := setupRequest(client, "PATCH", url, jsonBody)
+func patch(ctx context.Context, client *Client, url string, jsonBody *bytes.Buffer) (*http.Response, error) {
+	req, err := setupRequest(ctx, client, "PATCH", url, jsonBody)
 	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return executeRequest(client, req)
+	return executeMutation(client, req)
 }
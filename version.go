@@ -0,0 +1,290 @@
+package aoscxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported is returned when the REST API version in use does not support
+// a requested feature, or when no version within MinVersion and MaxVersion is
+// offered by the switch.
+var ErrUnsupported = errors.New("unsupported by REST API version")
+
+// APIVersion is a parsed AOS-CX REST API version such as "v10.09".
+type APIVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseAPIVersion parses versions in the forms "v10.09", "10.09" and "10.9".
+func ParseAPIVersion(version string) (APIVersion, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	major_str, minor_str, found := strings.Cut(trimmed, ".")
+	if !found {
+		return APIVersion{}, fmt.Errorf("invalid API version %q", version)
+	}
+	major, err := strconv.Atoi(major_str)
+	if err != nil || major < 0 {
+		return APIVersion{}, fmt.Errorf("invalid API version %q", version)
+	}
+	minor, err := strconv.Atoi(minor_str)
+	if err != nil || minor < 0 {
+		return APIVersion{}, fmt.Errorf("invalid API version %q", version)
+	}
+	return APIVersion{Major: major, Minor: minor}, nil
+}
+
+// String returns the version in the form used in REST URLs, e.g. "v10.09".
+func (v APIVersion) String() string {
+	return fmt.Sprintf("v%d.%02d", v.Major, v.Minor)
+}
+
+// IsZero reports whether v is the zero APIVersion.
+func (v APIVersion) IsZero() bool {
+	return v.Major == 0 && v.Minor == 0
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than other.
+func (v APIVersion) Compare(other APIVersion) int {
+	switch {
+	case v.Major != other.Major:
+		if v.Major < other.Major {
+			return -1
+		}
+		return 1
+	case v.Minor < other.Minor:
+		return -1
+	case v.Minor > other.Minor:
+		return 1
+	}
+	return 0
+}
+
+// Less reports whether v is older than other.
+func (v APIVersion) Less(other APIVersion) bool {
+	return v.Compare(other) < 0
+}
+
+// AtLeast reports whether v is the same as or newer than other.
+func (v APIVersion) AtLeast(other APIVersion) bool {
+	return v.Compare(other) >= 0
+}
+
+// Capability names a REST API feature whose availability depends on the API version.
+type Capability string
+
+// Capabilities used by the resources of this package.
+const (
+	CapabilityVlans            Capability = "system/vlans"
+	CapabilityInterfaces       Capability = "system/interfaces"
+	CapabilityLagInterfaces    Capability = "system/interfaces type lag"
+	CapabilityVlanInterfaces   Capability = "system/interfaces type vlan"
+	CapabilityIP6Addresses     Capability = "system/interfaces ip6_addresses"
+	CapabilityWritableSelector Capability = "selector=writable"
+	CapabilityConfigDryRun     Capability = "configs/running-config dryrun"
+)
+
+// AttributeCapability names the capability of writing attribute to the rows of
+// table, e.g. AttributeCapability("system/interfaces", "vlan_mode"). Request
+// bodies sent to the switch are checked against these entries.
+func AttributeCapability(table, attribute string) Capability {
+	return Capability(table + " " + attribute)
+}
+
+// The L2 and L3 configuration of a port was kept in system/ports up to v10.04
+// and moved to system/interfaces in v10.08, together with LAGs, VLAN interfaces
+// and their ip6_addresses. The entries are keyed by table, so a request to an
+// unsupported table fails too.
+var (
+	capabilitiesMu sync.RWMutex
+	capabilities   = map[Capability]APIVersion{
+		CapabilityVlans:            {Major: 10, Minor: 4},
+		CapabilityInterfaces:       {Major: 10, Minor: 4},
+		CapabilityWritableSelector: {Major: 10, Minor: 4},
+		CapabilityLagInterfaces:    {Major: 10, Minor: 8},
+		CapabilityVlanInterfaces:   {Major: 10, Minor: 8},
+		CapabilityIP6Addresses:     {Major: 10, Minor: 8},
+		CapabilityConfigDryRun:     {Major: 10, Minor: 9},
+
+		AttributeCapability("system/interfaces", "routing"):               {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "vlan_mode"):             {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "vlan_tag"):              {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "vlan_trunks"):           {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "vrf"):                   {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "ip4_address"):           {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "ip4_address_secondary"): {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "lacp"):                  {Major: 10, Minor: 8},
+		AttributeCapability("system/interfaces", "interfaces"):            {Major: 10, Minor: 8},
+	}
+)
+
+// RegisterCapability records the minimum REST API version that supports capability,
+// replacing any previous entry. It can be used to describe attributes and tables
+// that are not modelled by this package.
+func RegisterCapability(capability Capability, minimum APIVersion) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	capabilities[capability] = minimum
+}
+
+// MinimumVersion returns the minimum REST API version registered for capability.
+func MinimumVersion(capability Capability) (APIVersion, bool) {
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+	minimum, ok := capabilities[capability]
+	return minimum, ok
+}
+
+// CapabilityError is returned when the REST API version in use is older than the
+// minimum version registered for a capability.
+type CapabilityError struct {
+	Capability Capability
+	Required   APIVersion
+	Actual     APIVersion
+}
+
+// Error implements the error interface
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("%s requires %s, switch is using %s", e.Capability, e.Required, e.Actual)
+}
+
+// Is reports ErrUnsupported
+func (e *CapabilityError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// APIVersion returns the parsed REST API version the client uses.
+func (c *Client) APIVersion() (APIVersion, error) {
+	return ParseAPIVersion(c.Version)
+}
+
+// Supports reports whether the REST API version of the client supports capability.
+// Unregistered capabilities, and clients whose version is not known yet, are
+// assumed to be supported.
+func (c *Client) Supports(capability Capability) bool {
+	return c.requireCapability(capability) == nil
+}
+
+// requireCapability returns a CapabilityError for the first capability the REST
+// API version of the client does not support.
+func (c *Client) requireCapability(required ...Capability) error {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil
+	}
+	for _, capability := range required {
+		minimum, ok := MinimumVersion(capability)
+		if ok && version.Less(minimum) {
+			return &CapabilityError{Capability: capability, Required: minimum, Actual: version}
+		}
+	}
+	return nil
+}
+
+// requireRequestCapabilities checks req against the registry before it is sent:
+// the table it addresses, ?selector=writable, ?dryrun and every attribute of a
+// JSON object body.
+func (c *Client) requireRequestCapabilities(req *http.Request) error {
+	table, _ := resourceTable(req.URL)
+	required := []Capability{Capability(table)}
+
+	query := req.URL.Query()
+	if query.Get("selector") == string(SelectorWritable) {
+		required = append(required, CapabilityWritableSelector)
+	}
+	if query.Has("dryrun") {
+		required = append(required, CapabilityConfigDryRun)
+	}
+
+	body, err := requestBody(req)
+	if err != nil {
+		return err
+	}
+	var attributes map[string]json.RawMessage
+	if json.Unmarshal(body, &attributes) == nil {
+		names := make([]string, 0, len(attributes))
+		for name := range attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			required = append(required, AttributeCapability(table, name))
+		}
+	}
+	return c.requireCapability(required...)
+}
+
+// versionBounds parses MinVersion and MaxVersion, leaving unset bounds as zero values.
+func (c *Client) versionBounds() (APIVersion, APIVersion, error) {
+	var min_version, max_version APIVersion
+	var err error
+	if c.MinVersion != "" {
+		if min_version, err = ParseAPIVersion(c.MinVersion); err != nil {
+			return APIVersion{}, APIVersion{}, fmt.Errorf("MinVersion: %w", err)
+		}
+	}
+	if c.MaxVersion != "" {
+		if max_version, err = ParseAPIVersion(c.MaxVersion); err != nil {
+			return APIVersion{}, APIVersion{}, fmt.Errorf("MaxVersion: %w", err)
+		}
+	}
+	return min_version, max_version, nil
+}
+
+// withinBounds reports whether version satisfies MinVersion and MaxVersion.
+func withinBounds(version, min_version, max_version APIVersion) bool {
+	if !min_version.IsZero() && version.Less(min_version) {
+		return false
+	}
+	if !max_version.IsZero() && max_version.Less(version) {
+		return false
+	}
+	return true
+}
+
+// negotiateVersion picks the newest of the versions offered by the switch that
+// satisfies MinVersion and MaxVersion.
+func (c *Client) negotiateVersion(offered []string) (string, error) {
+	min_version, max_version, err := c.versionBounds()
+	if err != nil {
+		return "", err
+	}
+
+	var best APIVersion
+	for _, version_str := range offered {
+		version, err := ParseAPIVersion(version_str)
+		if err != nil || !withinBounds(version, min_version, max_version) {
+			continue
+		}
+		if best.Less(version) {
+			best = version
+		}
+	}
+	if best.IsZero() {
+		return "", fmt.Errorf("%w: switch offers %s, client requires between %q and %q",
+			ErrUnsupported, strings.Join(offered, ", "), c.MinVersion, c.MaxVersion)
+	}
+	return best.String(), nil
+}
+
+// checkVersionBounds verifies that the configured Version satisfies MinVersion and MaxVersion.
+func (c *Client) checkVersionBounds() error {
+	min_version, max_version, err := c.versionBounds()
+	if err != nil {
+		return err
+	}
+	version, err := c.APIVersion()
+	if err != nil {
+		return err
+	}
+	if !withinBounds(version, min_version, max_version) {
+		return fmt.Errorf("%w: version %s is outside of %q and %q", ErrUnsupported, version, c.MinVersion, c.MaxVersion)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package aoscxgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+	"github.com/felixn-unity/aoscxgo/aoscxtest"
+)
+
+// registerCapability registers capability for the duration of the test and
+// then restores the previous registry entry.
+func registerCapability(t *testing.T, capability aoscxgo.Capability, minimum aoscxgo.APIVersion) {
+	t.Helper()
+	previous, ok := aoscxgo.MinimumVersion(capability)
+	t.Cleanup(func() {
+		if ok {
+			aoscxgo.RegisterCapability(capability, previous)
+		} else {
+			aoscxgo.UnregisterCapability(capability)
+		}
+	})
+	aoscxgo.RegisterCapability(capability, minimum)
+}
+
+func TestRegisterCapabilityRestored(t *testing.T) {
+	added := aoscxgo.AttributeCapability("system/vlans", "restored_attribute")
+	t.Run("register", func(t *testing.T) {
+		registerCapability(t, added, aoscxgo.APIVersion{Major: 10, Minor: 11})
+		registerCapability(t, aoscxgo.CapabilityConfigDryRun, aoscxgo.APIVersion{Major: 10, Minor: 13})
+	})
+
+	if minimum, ok := aoscxgo.MinimumVersion(added); ok {
+		t.Errorf("MinimumVersion(%s) = %v after the test, want it unregistered", added, minimum)
+	}
+	if minimum, _ := aoscxgo.MinimumVersion(aoscxgo.CapabilityConfigDryRun); minimum != (aoscxgo.APIVersion{Major: 10, Minor: 9}) {
+		t.Errorf("MinimumVersion(%s) = %v after the test, want v10.09", aoscxgo.CapabilityConfigDryRun, minimum)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	registerCapability(t, aoscxgo.AttributeCapability("system/vlans", "test_attribute"), aoscxgo.APIVersion{Major: 10, Minor: 11})
+
+	tests := []struct {
+		name     string
+		version  string
+		run      func(sw *aoscxgo.Client) error
+		wantErr  aoscxgo.Capability
+		required aoscxgo.APIVersion
+	}{
+		{
+			name:    "Vlan on v10.04",
+			version: "v10.04",
+			run: func(sw *aoscxgo.Client) error {
+				vlan := aoscxgo.Vlan{VlanId: 100, Name: "uplink"}
+				return vlan.Create(sw)
+			},
+		},
+		{
+			name:    "L2Interface attributes on v10.04",
+			version: "v10.04",
+			run: func(sw *aoscxgo.Client) error {
+				l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1", AdminState: "up"}}
+				return l2.Create(sw)
+			},
+			wantErr:  "system/interfaces routing",
+			required: aoscxgo.APIVersion{Major: 10, Minor: 8},
+		},
+		{
+			name:    "LagInterface on v10.04",
+			version: "v10.04",
+			run: func(sw *aoscxgo.Client) error {
+				lag := aoscxgo.LagInterface{Name: "lag10", AdminState: "up"}
+				return lag.Create(sw)
+			},
+			wantErr:  aoscxgo.CapabilityLagInterfaces,
+			required: aoscxgo.APIVersion{Major: 10, Minor: 8},
+		},
+		{
+			name:    "low-level PATCH on v10.04",
+			version: "v10.04",
+			run: func(sw *aoscxgo.Client) error {
+				return sw.Patch(context.Background(), "system/interfaces/1%2F1%2F1", map[string]interface{}{"vlan_mode": "access"})
+			},
+			wantErr:  "system/interfaces vlan_mode",
+			required: aoscxgo.APIVersion{Major: 10, Minor: 8},
+		},
+		{
+			name:    "L2Interface on v10.08",
+			version: "v10.08",
+			run: func(sw *aoscxgo.Client) error {
+				l2 := aoscxgo.L2Interface{Interface: aoscxgo.Interface{Name: "1/1/1", AdminState: "up"}}
+				return l2.Create(sw)
+			},
+		},
+		{
+			name:    "registered attribute on v10.09",
+			version: "v10.09",
+			run: func(sw *aoscxgo.Client) error {
+				return sw.Patch(context.Background(), "system/vlans/1", map[string]interface{}{"test_attribute": true})
+			},
+			wantErr:  "system/vlans test_attribute",
+			required: aoscxgo.APIVersion{Major: 10, Minor: 11},
+		},
+		{
+			name:    "full config dryrun on v10.08",
+			version: "v10.08",
+			run: func(sw *aoscxgo.Client) error {
+				config := aoscxgo.FullConfig{}
+				_, _, err := config.ValidateConfig(sw, "hostname test\n")
+				return err
+			},
+			wantErr:  aoscxgo.CapabilityConfigDryRun,
+			required: aoscxgo.APIVersion{Major: 10, Minor: 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := aoscxtest.NewServer()
+			t.Cleanup(srv.Close)
+			srv.SetVersions(tt.version)
+			sw, err := aoscxgo.Connect(srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sw.Version != tt.version {
+				t.Fatalf("Version = %s, want %s", sw.Version, tt.version)
+			}
+			srv.ResetRequests()
+
+			err = tt.run(sw)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				return
+			}
+
+			var capability_err *aoscxgo.CapabilityError
+			if !errors.As(err, &capability_err) || !errors.Is(err, aoscxgo.ErrUnsupported) {
+				t.Fatalf("error = %v, want a *CapabilityError", err)
+			}
+			if capability_err.Capability != tt.wantErr || capability_err.Required != tt.required || capability_err.Actual.String() != tt.version {
+				t.Errorf("CapabilityError = %+v, want %s requiring %s", capability_err, tt.wantErr, tt.required)
+			}
+			for _, req := range srv.Requests() {
+				if req.Method != http.MethodGet {
+					t.Errorf("%s %s sent to the switch", req.Method, req.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	sw := &aoscxgo.Client{Version: "v10.04"}
+	if !sw.Supports(aoscxgo.CapabilityVlans) || sw.Supports(aoscxgo.CapabilityVlanInterfaces) {
+		t.Errorf("Supports() on v10.04 = %v, %v", sw.Supports(aoscxgo.CapabilityVlans), sw.Supports(aoscxgo.CapabilityVlanInterfaces))
+	}
+	if !sw.Supports("system/unknown") {
+		t.Error("Supports() of an unregistered capability = false")
+	}
+}
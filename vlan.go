@@ -2,8 +2,8 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strconv"
 )
@@ -11,26 +11,37 @@ import (
 type Vlan struct {
 
 	// Connection properties.
-	VlanId       int                    `json:"id"`
-	Name         string                 `json:"name"`
-	Description  string                 `json:"description"`
-	AdminState   string                 `json:"admin_state"`
-	VlanDetails  map[string]interface{} `json:"details"`
-	materialized bool                   `json:"materialized"`
-	uri          string                 `json:"uri"`
+	VlanId       int            `json:"id"`
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	AdminState   string         `json:"admin_state"`
+	VlanDetails  VlanAttributes `json:"details"`
+	Mask         FieldMask      `json:"-"`
+	materialized bool
+	uri          string
 }
 
 // Create performs POST to create VLAN configuration on the given Client object.
 func (v *Vlan) Create(c *Client) error {
+	return v.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (v *Vlan) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", v.Kind(), v.Key())
+	if err := c.requireCapability(CapabilityVlans); err != nil {
+		return err
+	}
+
 	base_uri := "system/vlans"
 	vlan_str := strconv.Itoa(v.VlanId)
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
+	url := c.restURL() + "/" + base_uri
 	v.uri = "/rest/" + c.Version + "/" + base_uri + "/" + vlan_str
 
 	if v.VlanId == 0 || v.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Required Values VlanId & Name",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -51,13 +62,13 @@ func (v *Vlan) Create(c *Client) error {
 
 	json_body := bytes.NewBuffer(postBody)
 
-	res := post(c, url, json_body)
+	res, err := post(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "201 Created" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Create Error"),
-		}
+		return newAPIError("Create Error", res)
 	}
 
 	v.materialized = true
@@ -66,36 +77,56 @@ func (v *Vlan) Create(c *Client) error {
 }
 
 // Update performs PATCH to update VLAN configuration on the given Client object.
+// Only the attributes that differ from the writable state of the VLAN are sent,
+// and empty fields are left unchanged unless they are listed in Mask.
 func (v *Vlan) Update(c *Client) error {
+	return v.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (v *Vlan) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", v.Kind(), v.Key())
+	if err := c.requireCapability(CapabilityVlans); err != nil {
+		return err
+	}
+
 	base_uri := "system/vlans"
 	vlan_str := strconv.Itoa(v.VlanId)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_str
+	url := c.restURL() + "/" + base_uri + "/" + vlan_str
 
 	if v.VlanId == 0 || v.Name == "" {
 		return &RequestError{
 			StatusCode: "Missing Required Values VlanId & Name",
-			Err:        errors.New("Update Error"),
+			Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 		}
 	}
-	patchMap := map[string]interface{}{
+
+	changed, err := changes(ctx, c, v)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	patchMap := changedBody(map[string]interface{}{
 		"name":        v.Name,
 		"description": v.Description,
 		"admin":       v.AdminState,
-		"type":        "static", //default value
-	}
+	}, changed)
 
 	patchBody, _ := json.Marshal(patchMap)
 
 	json_body := bytes.NewBuffer(patchBody)
 
-	res := patch(c, url, json_body)
+	res, err := patch(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.Status != "204 No Content" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Update Error"),
-		}
+		return newAPIError("Update Error", res)
 	}
 
 	return nil
@@ -103,35 +134,48 @@ func (v *Vlan) Update(c *Client) error {
 
 // Delete performs DELETE to remove VLAN configuration from the given Client object.
 func (v *Vlan) Delete(c *Client) error {
+	return v.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (v *Vlan) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", v.Kind(), v.Key())
+	if err := c.requireCapability(CapabilityVlans); err != nil {
+		return err
+	}
+
 	base_uri := "system/vlans"
 	// Check if Vlan Interface exists, if so then fail
 	vlan_interface_id := fmt.Sprintf("vlan%d", v.VlanId)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id
+	url := c.restURL() + "/system/interfaces/" + vlan_interface_id
 
-	res, _ := get(c, url)
+	res, _, err := get(ctx, c, url)
+	if err != nil {
+		return err
+	}
 
 	// If VLAN interface exists (200 OK), we need to fail - it must be deleted first
 	if res.Status == "200 OK" {
-		err_str := "VlanInterface " + vlan_interface_id + " exists - delete VlanInterface before deleting Vlan " + vlan_interface_id
+		err_str := "VlanInterface " + vlan_interface_id + " exists - delete VlanInterface before deleting Vlan " + strconv.Itoa(v.VlanId)
 		return &RequestError{
 			StatusCode: err_str,
-			Err:        errors.New("Delete Error"),
+			Err:        fmt.Errorf("Delete Error: %w", ErrDependency),
 		}
 	}
 	// 404 is expected here - means no VLAN interface exists, which is what we want
 
 	vlan_str := strconv.Itoa(v.VlanId)
 
-	url = "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_str
-	res = delete(c, url)
+	url = c.restURL() + "/" + base_uri + "/" + vlan_str
+	res, err = delete(ctx, c, url)
+	if err != nil {
+		return err
+	}
 
 	// Success cases: 204 No Content (deleted) or 404 Not Found (already doesn't exist)
 	if res.Status != "204 No Content" && res.Status != "404 Not Found" {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Delete Error"),
-		}
+		return newAPIError("Delete Error", res)
 	}
 
 	return nil
@@ -139,44 +183,46 @@ func (v *Vlan) Delete(c *Client) error {
 
 // Get performs GET to retrieve VLAN configuration for the given Client object.
 func (v *Vlan) Get(c *Client) error {
+	return v.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (v *Vlan) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityVlans); err != nil {
+		return err
+	}
+
 	base_uri := "system/vlans"
 	vlan_str := strconv.Itoa(v.VlanId)
 	v.uri = "/rest/" + c.Version + "/" + base_uri + "/" + vlan_str
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_str
+	url := c.restURL() + "/" + base_uri + "/" + vlan_str
 
-	res, body := get(c, url)
-
-	if res.Status != "200 OK" {
+	var attributes VlanAttributes
+	if err := getJSON(ctx, c, "Retrieval Error", url, &attributes); err != nil {
 		v.materialized = false
-		return &RequestError{
-			StatusCode: res.Status + url,
-			Err:        errors.New("Retrieval Error"),
-		}
-	}
-
-	if v.VlanDetails == nil {
-		v.VlanDetails = map[string]interface{}{}
+		return err
 	}
 
-	for key, value := range body {
-		v.VlanDetails[key] = value
-		if key == "name" && value != nil {
-			v.Name = value.(string)
-		}
-		if key == "description" && value != nil {
-			v.Description = value.(string)
-		}
+	v.loadDetails(attributes)
 
-		if key == "admin" {
-			v.AdminState = value.(string)
-		}
+	return nil
+}
 
+// loadDetails populates the VLAN from the attributes returned by the switch.
+func (v *Vlan) loadDetails(attributes VlanAttributes) {
+	v.VlanDetails = attributes
+	if attributes.Has("name") {
+		v.Name = attributes.Name
+	}
+	if attributes.Has("description") {
+		v.Description = attributes.Description
+	}
+	if attributes.Has("admin") {
+		v.AdminState = attributes.Admin
 	}
 
 	v.materialized = true
-
-	return nil
 }
 
 // GetStatus returns True if VLAN exists on Client object or False if not.
@@ -188,3 +234,72 @@ func (v *Vlan) GetStatus() bool {
 func (v *Vlan) GetURI() string {
 	return v.uri
 }
+
+// Kind returns "Vlan".
+func (v *Vlan) Kind() string {
+	return "Vlan"
+}
+
+// Key returns the VLAN ID as a string.
+func (v *Vlan) Key() string {
+	return strconv.Itoa(v.VlanId)
+}
+
+// URI returns the path of the VLAN relative to the versioned REST API.
+func (v *Vlan) URI() string {
+	return "system/vlans/" + v.Key()
+}
+
+// Exists reports whether the VLAN is configured on the switch.
+func (v *Vlan) Exists(c *Client) (bool, error) {
+	return v.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (v *Vlan) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, v, CapabilityVlans)
+}
+
+// Ensure creates the VLAN, or updates its Name, Description and AdminState when they differ from the switch.
+func (v *Vlan) Ensure(c *Client) (EnsureResult, error) {
+	return v.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (v *Vlan) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, v)
+}
+
+// EnsureAbsent deletes the VLAN if it is configured.
+func (v *Vlan) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return v.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (v *Vlan) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureAbsent(ctx, c, v)
+}
+
+// state returns the attributes of the VLAN, see stateful.
+func (v *Vlan) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, v.Mask)
+	state.add("name", v.Name, v.Name != "")
+	state.add("description", v.Description, v.Description != "")
+	state.add("admin", v.AdminState, v.AdminState != "")
+	return state.values
+}
+
+// writableState reads the writable attributes of the VLAN from the switch.
+func (v *Vlan) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	if err := c.requireCapability(CapabilityVlans, CapabilityWritableSelector); err != nil {
+		return nil, err
+	}
+
+	var attributes VlanAttributes
+	if err := c.Get(ctx, v.URI(), &QueryOptions{Selector: SelectorWritable}, &attributes); err != nil {
+		return nil, err
+	}
+	current := Vlan{VlanId: v.VlanId}
+	current.loadDetails(attributes)
+	return current.state(false), nil
+}
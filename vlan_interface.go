@@ -2,10 +2,9 @@ package aoscxgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -16,17 +15,28 @@ import (
 type VlanInterface struct {
 
 	// Connection properties.
-	Vlan             Vlan                   `json:"vlan"`
-	Description      string                 `json:"description"`
-	Ipv4             []interface{}          `json:"ipv4"`
-	Ipv6             []interface{}          `json:"ipv6"`
-	Vrf              string                 `json:"vrf"`
-	InterfaceDetails map[string]interface{} `json:"details"`
-	materialized     bool                   `json:"materialized"`
+	Vlan             Vlan                `json:"vlan"`
+	Description      string              `json:"description"`
+	Ipv4             []string            `json:"ipv4"`
+	Ipv6             []string            `json:"ipv6"`
+	Vrf              string              `json:"vrf"`
+	InterfaceDetails InterfaceAttributes `json:"details"`
+	Mask             FieldMask           `json:"-"`
+	materialized     bool
 }
 
 // Create performs POST to create VlanInterface configuration on the given Client object.
 func (v *VlanInterface) Create(c *Client) error {
+	return v.CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create but uses ctx for every request made to the switch.
+func (v *VlanInterface) CreateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "create", v.Kind(), v.Key())
+	if err := c.requireCapability(CapabilityVlanInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 
 	vlan_interface_id := fmt.Sprintf("vlan%d", v.Vlan.VlanId)
@@ -36,7 +46,7 @@ func (v *VlanInterface) Create(c *Client) error {
 	if v.Vlan.VlanId == 0 {
 		return &RequestError{
 			StatusCode: "Missing Required Values VlanId",
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 		}
 	}
 
@@ -45,16 +55,16 @@ func (v *VlanInterface) Create(c *Client) error {
 		VlanId: v.Vlan.VlanId,
 	}
 
-	err := tmp_vlan.Get(c)
+	err := tmp_vlan.GetContext(ctx, c)
 
 	if err != nil {
 		return &RequestError{
 			StatusCode: fmt.Sprintf("Missing VLAN %d - Create Vlan before VlanInterface", v.Vlan.VlanId),
-			Err:        errors.New("Create Error"),
+			Err:        fmt.Errorf("Create Error: %w", ErrDependency),
 		}
 	}
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri
+	url := c.restURL() + "/" + base_uri
 
 	postMap["description"] = v.Description
 	postMap["admin"] = v.Vlan.AdminState
@@ -75,7 +85,7 @@ func (v *VlanInterface) Create(c *Client) error {
 		postMap["ip4_address"] = nil
 		postMap["ip4_address_secondary"] = nil
 	} else if len(v.Ipv4) == 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", v.Ipv4[0])
+		str_ipv4_1 := v.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			postMap["ip4_address"] = str_ipv4_1
 			postMap["ip4_address_secondary"] = nil
@@ -83,33 +93,31 @@ func (v *VlanInterface) Create(c *Client) error {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in same ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Create Error"),
+				Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 			}
 		}
 	} else if len(v.Ipv4) > 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", v.Ipv4[0])
+		str_ipv4_1 := v.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			postMap["ip4_address"] = v.Ipv4[0]
 		} else {
-			log.Printf("Invalid IPv4 address format: %s", str_ipv4_1)
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Create Error"),
+				Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 			}
 		}
 
 		var tmp_splice []string
 		for index := 1; index < len(v.Ipv4); index++ {
-			str_ipv4_tmp := fmt.Sprintf("%v", v.Ipv4[index])
+			str_ipv4_tmp := v.Ipv4[index]
 			if checkIPAddress(str_ipv4_tmp) {
 				tmp_splice = append(tmp_splice, str_ipv4_tmp)
 			} else {
-				log.Printf("Invalid IPv4 address format: %s", str_ipv4_tmp)
 				status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_tmp
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Create Error"),
+					Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 				}
 			}
 		}
@@ -128,18 +136,15 @@ func (v *VlanInterface) Create(c *Client) error {
 
 	json_body := bytes.NewBuffer(postBody)
 
-	res := post(c, url, json_body)
+	res, err := post(ctx, c, url, json_body)
+	if err != nil {
+		return err
+	}
 
 	if res.StatusCode != http.StatusCreated {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Create Error"),
-		}
+		return newAPIError("Create Error", res)
 	}
 
-	// string to track ipv6 Create success
-	failed_ipv6 := ""
-
 	if len(v.Ipv6) == 0 {
 		// What are default values when no ipv6 but routing enabled
 		postMap["ip6_addresses"] = nil
@@ -147,42 +152,38 @@ func (v *VlanInterface) Create(c *Client) error {
 		// two values for ipv6? how is that affected
 		// first create POST to add an ipv6 address Object
 		//
-		for _, ip_address := range v.Ipv6 {
-			if _, ok := ip_address.(string); ok {
-				str_ipv6 := fmt.Sprintf("%v", ip_address)
-				if checkIPAddress(str_ipv6) {
-					ipv6Map := map[string]interface{}{}
-					ipv6Map["address"] = str_ipv6
-					ipv6Map["type"] = "global-unicast"
-					ipv6Map["preferred_lifetime"] = 604800
-					ipv6Map["valid_lifetime"] = 2592000
-					ipv6Map["node_address"] = true
-					ipv6Map["ra_prefix"] = true
-					ipv6Map["ra_route"] = false
-
-					ipv6body, _ := json.Marshal(ipv6Map)
-
-					json_body := bytes.NewBuffer(ipv6body)
-
-					ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
-
-					res := post(c, ip6_url, json_body)
-
-					if res.StatusCode != http.StatusCreated {
-						failed_ipv6 = fmt.Sprintf("\nip6_addresses failed to create %v\nstatus code %v", v.Ipv6, res.Status)
-						return &RequestError{
-							StatusCode: failed_ipv6,
-							Err:        errors.New("Create Error"),
-						}
-					}
-
-				} else {
-					status_str := "Invalid Required Value: Ipv6 - ensure addresses are in ipv6 address/mask format:" +
-						ip_address.(string)
-					return &RequestError{
-						StatusCode: status_str,
-						Err:        errors.New("Create Error"),
-					}
+		for _, str_ipv6 := range v.Ipv6 {
+			if checkIPAddress(str_ipv6) {
+				ipv6Map := map[string]interface{}{}
+				ipv6Map["address"] = str_ipv6
+				ipv6Map["type"] = "global-unicast"
+				ipv6Map["preferred_lifetime"] = 604800
+				ipv6Map["valid_lifetime"] = 2592000
+				ipv6Map["node_address"] = true
+				ipv6Map["ra_prefix"] = true
+				ipv6Map["ra_route"] = false
+
+				ipv6body, _ := json.Marshal(ipv6Map)
+
+				json_body := bytes.NewBuffer(ipv6body)
+
+				ip6_url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
+
+				res, err := post(ctx, c, ip6_url, json_body)
+				if err != nil {
+					return err
+				}
+
+				if res.StatusCode != http.StatusCreated {
+					return newAPIError("ip6_addresses failed to create "+str_ipv6, res)
+				}
+
+			} else {
+				status_str := "Invalid Required Value: Ipv6 - ensure addresses are in ipv6 address/mask format:" +
+					str_ipv6
+				return &RequestError{
+					StatusCode: status_str,
+					Err:        fmt.Errorf("Create Error: %w", ErrValidation),
 				}
 			}
 		}
@@ -194,8 +195,36 @@ func (v *VlanInterface) Create(c *Client) error {
 	return nil
 }
 
-// Update performs PATCH or PUT to update VlanInterface configuration on the given Client object.
-func (v *VlanInterface) Update(c *Client, use_put bool) error {
+// Update performs PATCH to update VlanInterface configuration on the given Client object.
+// Attributes equal to the switch are not sent, nor are empty fields missing from Mask.
+func (v *VlanInterface) Update(c *Client) error {
+	return v.UpdateContext(context.Background(), c)
+}
+
+// UpdateContext is like Update but uses ctx for every request made to the switch.
+func (v *VlanInterface) UpdateContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "update", v.Kind(), v.Key())
+	return v.update(ctx, c, false)
+}
+
+// Replace performs PUT to replace VlanInterface configuration on the given Client object.
+// Writable attributes not managed by the VlanInterface are read first and sent back unchanged.
+func (v *VlanInterface) Replace(c *Client) error {
+	return v.ReplaceContext(context.Background(), c)
+}
+
+// ReplaceContext is like Replace but uses ctx for every request made to the switch.
+func (v *VlanInterface) ReplaceContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "replace", v.Kind(), v.Key())
+	return v.update(ctx, c, true)
+}
+
+// update sends the configuration with PATCH, or with PUT when use_put is set.
+func (v *VlanInterface) update(ctx context.Context, c *Client, use_put bool) error {
+	if err := c.requireCapability(CapabilityVlanInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 
 	updateMap := map[string]interface{}{}
@@ -204,16 +233,30 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 
 	tmp_vlan_int := VlanInterface{Vlan: v.Vlan}
 
+	// A PATCH only carries the attributes that differ from the switch
+	var changed []Difference
+	if !use_put {
+		var err error
+		changed, err = changes(ctx, c, v)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			v.materialized = true
+			return nil
+		}
+	}
+
 	if use_put {
-		err := tmp_vlan_int.Get(c)
+		err := tmp_vlan_int.GetContext(ctx, c)
 		if err != nil {
 			error_str := "Missing VlanInterface - " + vlan_interface_id
 			return &RequestError{
 				StatusCode: error_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", err),
 			}
 		}
-		for key, value := range tmp_vlan_int.InterfaceDetails {
+		for key, value := range tmp_vlan_int.InterfaceDetails.values() {
 			updateMap[key] = value
 		}
 	}
@@ -231,7 +274,7 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 		updateMap["ip4_address"] = nil
 		updateMap["ip4_address_secondary"] = nil
 	} else if len(v.Ipv4) == 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", v.Ipv4[0])
+		str_ipv4_1 := v.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			updateMap["ip4_address"] = str_ipv4_1
 			updateMap["ip4_address_secondary"] = nil
@@ -239,103 +282,98 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 			}
 		}
 
 	} else if len(v.Ipv4) > 1 {
-		str_ipv4_1 := fmt.Sprintf("%v", v.Ipv4[0])
+		str_ipv4_1 := v.Ipv4[0]
 		if checkIPAddress(str_ipv4_1) {
 			updateMap["ip4_address"] = v.Ipv4[0]
 		} else {
 			status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_1
 			return &RequestError{
 				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
+				Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 			}
 		}
 
 		var tmp_splice []string
 		for index := 1; index < len(v.Ipv4); index++ {
-			str_ipv4_tmp := fmt.Sprintf("%v", v.Ipv4[index])
-			log.Printf("Processing IPv4 address: %s", str_ipv4_tmp)
+			str_ipv4_tmp := v.Ipv4[index]
 			if checkIPAddress(str_ipv4_tmp) {
 				tmp_splice = append(tmp_splice, str_ipv4_tmp)
 			} else {
 				status_str := "Invalid Required Value: Ipv4 - ensure addresses are in ipv4 format: " + str_ipv4_tmp
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Update Error"),
+					Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 				}
 			}
 		}
 		updateMap["ip4_address_secondary"] = tmp_splice
 	}
 
-	if len(v.Ipv6) == 0 {
+	if !use_put && !hasDifference(changed, "ip6_addresses") {
+		// The ip6_addresses are left as they are
+	} else if len(v.Ipv6) == 0 {
 		// What are default values when no ipv6 but routing enabled
 		updateMap["ip6_addresses"] = nil
 		// retrieve what's existing on the switch and remove
-		ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
-		res, body := get(c, ip6_url)
+		ip6_url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
+		res, body, err := get(ctx, c, ip6_url)
+		if err != nil {
+			return err
+		}
 
 		if res.StatusCode != http.StatusOK {
-			return &RequestError{
-				StatusCode: res.Status,
-				Err:        errors.New("Retrieval Error"),
-			}
+			return newAPIError("Retrieval Error", res)
 		}
 		for key, _ := range body {
 			tmp_ip6_str := url.QueryEscape(key)
 			del_ip6_url := ip6_url + "/" + tmp_ip6_str
-			res := delete(c, del_ip6_url)
+			res, err := delete(ctx, c, del_ip6_url)
+			if err != nil {
+				return err
+			}
 
 			if res.StatusCode != http.StatusNoContent {
-				return &RequestError{
-					StatusCode: res.Status,
-					Err:        errors.New("Delete Error"),
-				}
+				return newAPIError("Delete Error", res)
 			}
 		}
 	} else if len(v.Ipv6) > 0 {
 		// two values for ipv6? how is that affected
 		// first create POST to add an ipv6 address Object
 		//
-		ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
+		ip6_url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
 
 		// execute GET to retrieve current IPs
 		// iterate over the list of IPs
 		// delete the ones that aren't provided
 
-		res, body := get(c, ip6_url)
+		res, body, err := get(ctx, c, ip6_url)
+		if err != nil {
+			return err
+		}
 
 		if res.StatusCode != http.StatusOK {
-			return &RequestError{
-				StatusCode: res.Status,
-				Err:        errors.New("Retrieval Error"),
-			}
+			return newAPIError("Retrieval Error", res)
 		}
 
-		var ipv6_slice []string
-		// convert i.Ipv6 from []interface{} to []string
-		for _, ip_address := range v.Ipv6 {
-			if _, ok := ip_address.(string); ok {
-				ipv6_slice = append(ipv6_slice, ip_address.(string))
-			}
-		}
+		ipv6_slice := v.Ipv6
 
 		var get_ipv6_slice []string
 		for key, _ := range body {
 			if !slices.Contains(ipv6_slice, key) {
 				tmp_ip6_str := url.QueryEscape(key)
 				del_ip6_url := ip6_url + "/" + tmp_ip6_str
-				res := delete(c, del_ip6_url)
+				res, err := delete(ctx, c, del_ip6_url)
+				if err != nil {
+					return err
+				}
 
 				if res.StatusCode != http.StatusNoContent {
-					return &RequestError{
-						StatusCode: res.Status,
-						Err:        errors.New("Delete Error"),
-					}
+					return newAPIError("Delete Error", res)
 				}
 
 			} else {
@@ -358,16 +396,14 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 
 				json_body := bytes.NewBuffer(ipv6body)
 
-				res := post(c, ip6_url, json_body)
+				res, err := post(ctx, c, ip6_url, json_body)
+				if err != nil {
+					return err
+				}
 
 				// include logic to check if address is existing?
 				if res.StatusCode != http.StatusCreated {
-					status_str := "ip6_addresses failed to update " +
-						str_ipv6 + " status " + res.Status
-					return &RequestError{
-						StatusCode: status_str,
-						Err:        errors.New("Update Error"),
-					}
+					return newAPIError("ip6_addresses failed to update "+str_ipv6, res)
 				}
 
 			} else if !checkIPAddress(str_ipv6) {
@@ -375,7 +411,7 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 					str_ipv6
 				return &RequestError{
 					StatusCode: status_str,
-					Err:        errors.New("Update Error"),
+					Err:        fmt.Errorf("Update Error: %w", ErrValidation),
 				}
 			}
 		}
@@ -389,7 +425,7 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 			"admin": "up"}
 	}
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id
+	url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id
 
 	updateMap["description"] = v.Description
 	updateMap["admin"] = v.Vlan.AdminState
@@ -406,30 +442,30 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 			"admin": "up"}
 	}
 
+	if !use_put {
+		updateMap = changedBody(updateMap, changed)
+	}
+
 	updateBody, _ := json.Marshal(updateMap)
 
 	json_body := bytes.NewBuffer(updateBody)
 
 	if use_put {
-		res := put(c, url, json_body)
+		res, err := put(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.StatusCode != http.StatusOK {
-			status_str := url + "||" + string(updateBody) + "PUT status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 
 	} else {
-		res := patch(c, url, json_body)
+		res, err := patch(ctx, c, url, json_body)
+		if err != nil {
+			return err
+		}
 		if res.StatusCode != http.StatusNoContent {
-			status_str := string(updateBody) + "PATCH status code = " + res.Status
-			return &RequestError{
-				//StatusCode: res.Status,
-				StatusCode: status_str,
-				Err:        errors.New("Update Error"),
-			}
+			return newAPIError("Update Error", res)
 		}
 	}
 
@@ -440,24 +476,34 @@ func (v *VlanInterface) Update(c *Client, use_put bool) error {
 
 // Delete performs DELETE to remove VlanInterface configuration from the given Client object.
 func (v *VlanInterface) Delete(c *Client) error {
+	return v.DeleteContext(context.Background(), c)
+}
+
+// DeleteContext is like Delete but uses ctx for every request made to the switch.
+func (v *VlanInterface) DeleteContext(ctx context.Context, c *Client) error {
+	ctx = withAuditTarget(ctx, "delete", v.Kind(), v.Key())
+	if err := c.requireCapability(CapabilityVlanInterfaces, CapabilityIP6Addresses); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if v.Vlan.VlanId == 0 {
 		return &RequestError{
 			StatusCode: "Missing VlanId unable to configure VlanInterface",
-			Err:        errors.New("Delete Error"),
+			Err:        fmt.Errorf("Delete Error: %w", ErrValidation),
 		}
 	}
 	vlan_interface_id := fmt.Sprintf("vlan%d", v.Vlan.VlanId)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id
+	url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id
 
-	res := delete(c, url)
+	res, err := delete(ctx, c, url)
+	if err != nil {
+		return err
+	}
 
 	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Delete Error"),
-		}
+		return newAPIError("Delete Error", res)
 	}
 
 	return nil
@@ -465,77 +511,87 @@ func (v *VlanInterface) Delete(c *Client) error {
 
 // Get performs GET to retrieve VlanInterface configuration from the given Client object.
 func (v *VlanInterface) Get(c *Client) error {
+	return v.GetContext(context.Background(), c)
+}
+
+// GetContext is like Get but uses ctx for every request made to the switch.
+func (v *VlanInterface) GetContext(ctx context.Context, c *Client) error {
+	if err := c.requireCapability(CapabilityVlanInterfaces, CapabilityIP6Addresses, CapabilityWritableSelector); err != nil {
+		return err
+	}
+
 	base_uri := "system/interfaces"
 	if v.Vlan.VlanId == 0 {
 		return &RequestError{
 			StatusCode: "Missing VlanId unable to configure VlanInterface",
-			Err:        errors.New("Get Error"),
+			Err:        fmt.Errorf("Get Error: %w", ErrValidation),
 		}
 	}
 	vlan_interface_id := fmt.Sprintf("vlan%d", v.Vlan.VlanId)
 
-	url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id + "?selector=writable"
+	url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id + "?selector=writable"
 
-	res, body := get(c, url)
+	var attributes InterfaceAttributes
+	if err := getJSON(ctx, c, "Retrieval Error", url, &attributes); err != nil {
+		v.materialized = false
+		return err
+	}
 
-	if res.StatusCode != http.StatusOK || len(body) <= 1 {
+	// An empty writable view means the interface row does not exist
+	if attributes.count() <= 1 {
 		v.materialized = false
 		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Retrieval Error"),
+			StatusCode: "VlanInterface " + vlan_interface_id + " has no configuration",
+			Err:        fmt.Errorf("Retrieval Error: %w", ErrNotFound),
 		}
 	}
 
-	if v.InterfaceDetails == nil {
-		v.InterfaceDetails = map[string]interface{}{}
-	}
+	v.loadDetails(attributes)
 
-	for key, value := range body {
-		v.InterfaceDetails[key] = value
-		if key == "description" && value != nil {
-			v.Description = value.(string)
-			v.Vlan.Description = value.(string)
-		}
+	// Include a GET for ip6 and populate .ipv6 attribute
 
-		if key == "admin" && value != nil {
-			v.Vlan.AdminState = value.(string)
-		}
+	ip6_url := c.restURL() + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
 
-		if key == "ip4_address" && value != nil {
-			var tmp_splice []interface{}
-			tmp_splice = append(tmp_splice, value.(string))
-			if val, ok := body["ip4_address_secondary"]; ok {
-				tmp_addr := val.([]interface{})
-				if len(tmp_addr) > 0 {
-					for index := 0; index < len(tmp_addr); index++ {
-						tmp_splice = append(tmp_splice, tmp_addr[index])
-					}
-				}
-			}
-			v.Ipv4 = tmp_splice
-		}
-
-		if key == "vrf" && value != nil {
-			for key, _ := range value.(map[string]interface{}) {
-				v.Vrf = key
-			}
-		}
+	res, body, err := get(ctx, c, ip6_url)
+	if err != nil {
+		return err
+	}
 
+	if res.StatusCode != http.StatusOK {
+		return newAPIError("Retrieval Error", res)
 	}
 
-	// Include a GET for ip6 and populate .ipv6 attribute
+	v.loadIP6Addresses(body)
+	v.materialized = true
 
-	ip6_url := "https://" + c.Hostname + "/rest/" + c.Version + "/" + base_uri + "/" + vlan_interface_id + "/" + "ip6_addresses"
+	return nil
+}
 
-	res, body = get(c, ip6_url)
+// loadDetails populates the VlanInterface from the writable attributes returned by the switch.
+func (v *VlanInterface) loadDetails(attributes InterfaceAttributes) {
+	v.InterfaceDetails = attributes
+	if attributes.Has("description") {
+		v.Description = attributes.Description
+		v.Vlan.Description = attributes.Description
+	}
 
-	if res.StatusCode != http.StatusOK {
-		return &RequestError{
-			StatusCode: res.Status,
-			Err:        errors.New("Retrieval Error"),
-		}
+	if attributes.Has("admin") {
+		v.Vlan.AdminState = attributes.Admin
+	}
+
+	if attributes.Ip4Address != "" {
+		tmp_splice := []string{attributes.Ip4Address}
+		tmp_splice = append(tmp_splice, attributes.Ip4AddressSecondary...)
+		v.Ipv4 = tmp_splice
 	}
 
+	for _, key := range attributes.Vrf.Keys() {
+		v.Vrf = key
+	}
+}
+
+// loadIP6Addresses sets Ipv6 from the keys of the ip6_addresses collection.
+func (v *VlanInterface) loadIP6Addresses(body map[string]interface{}) {
 	var ipv6_slice []string
 
 	for key, _ := range body {
@@ -544,22 +600,77 @@ func (v *VlanInterface) Get(c *Client) error {
 		}
 	}
 
-	ip6_addresses := make([]interface{}, len(ipv6_slice))
-
-	if len(ipv6_slice) > 0 {
-		for index := 0; index < len(ipv6_slice); index++ {
-			ip6_addresses[index] = ipv6_slice[index]
-		}
-	}
+	ip6_addresses := make([]string, len(ipv6_slice))
+	copy(ip6_addresses, ipv6_slice)
 
 	v.Ipv6 = ip6_addresses
-
-	v.materialized = true
-
-	return nil
 }
 
 // GetStatus returns True if VlanInterface exists on Client object or False if not.
 func (i *VlanInterface) GetStatus() bool {
 	return i.materialized
 }
+
+// Kind returns "VlanInterface".
+func (i *VlanInterface) Kind() string {
+	return "VlanInterface"
+}
+
+// Key returns the name of the interface, e.g. "vlan100".
+func (i *VlanInterface) Key() string {
+	return "vlan" + strconv.Itoa(i.Vlan.VlanId)
+}
+
+// URI returns the path of the VlanInterface relative to the versioned REST API.
+func (i *VlanInterface) URI() string {
+	return interfaceURI(i.Key())
+}
+
+// Exists reports whether the VLAN interface is configured on the switch.
+func (i *VlanInterface) Exists(c *Client) (bool, error) {
+	return i.ExistsContext(context.Background(), c)
+}
+
+// ExistsContext is like Exists but uses ctx for every request made to the switch.
+func (i *VlanInterface) ExistsContext(ctx context.Context, c *Client) (bool, error) {
+	return exists(ctx, c, i, CapabilityVlanInterfaces)
+}
+
+// Ensure creates the VlanInterface, or updates it when its description, admin state, VRF or addresses differ from the switch.
+func (v *VlanInterface) Ensure(c *Client) (EnsureResult, error) {
+	return v.EnsureContext(context.Background(), c)
+}
+
+// EnsureContext is like Ensure but uses ctx for every request made to the switch.
+func (v *VlanInterface) EnsureContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensure(ctx, c, v)
+}
+
+// EnsureAbsent deletes the VlanInterface if it is configured.
+func (v *VlanInterface) EnsureAbsent(c *Client) (EnsureResult, error) {
+	return v.EnsureAbsentContext(context.Background(), c)
+}
+
+// EnsureAbsentContext is like EnsureAbsent but uses ctx for every request made to the switch.
+func (v *VlanInterface) EnsureAbsentContext(ctx context.Context, c *Client) (EnsureResult, error) {
+	return ensureAbsent(ctx, c, v)
+}
+
+// state returns the attributes of the VlanInterface, see stateful.
+func (v *VlanInterface) state(desired bool) map[string]interface{} {
+	state := newAttributeState(desired, v.Mask)
+	state.add("description", v.Description, v.Description != "")
+	state.add("admin", v.Vlan.AdminState, v.Vlan.AdminState != "")
+	state.addRoutedState(v.Vrf, v.Ipv4, v.Ipv6)
+	return state.values
+}
+
+// writableState reads the writable attributes and ip6_addresses of the VLAN
+// interface from the switch.
+func (v *VlanInterface) writableState(ctx context.Context, c *Client) (map[string]interface{}, error) {
+	current := VlanInterface{Vlan: Vlan{VlanId: v.Vlan.VlanId}}
+	if err := current.GetContext(ctx, c); err != nil {
+		return nil, err
+	}
+	return current.state(false), nil
+}
@@ -0,0 +1,209 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestVlanInterfaceCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vi      aoscxgo.VlanInterface
+		wantErr error
+	}{
+		{
+			name: "IPv4 and IPv6",
+			vi: aoscxgo.VlanInterface{
+				Vlan: aoscxgo.Vlan{VlanId: 100, AdminState: "up"},
+				Ipv4: []string{"10.100.0.1/24", "10.100.1.1/24"},
+				Ipv6: []string{"2001:db8:100::1/64"},
+			},
+		},
+		{
+			name: "no addresses in VRF",
+			vi:   aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}, Vrf: "mgmt"},
+		},
+		{
+			name:    "missing VLAN id",
+			vi:      aoscxgo.VlanInterface{},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "missing VLAN",
+			vi:      aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 999}},
+			wantErr: aoscxgo.ErrDependency,
+		},
+		{
+			name:    "invalid IPv4",
+			vi:      aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}, Ipv4: []string{"10.100.0.1/99"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "invalid IPv6",
+			vi:      aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}, Ipv6: []string{"2001:db8:::1/64"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 100)
+
+			tt.vi.Description = "SVI"
+			err := tt.vi.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored, ok := srv.Interface("vlan100")
+			if !ok {
+				t.Fatal("VLAN interface not stored on switch")
+			}
+			if stored["type"] != "vlan" || stored["description"] != "SVI" {
+				t.Errorf("stored VLAN interface = %v", stored)
+			}
+			if got := referenceKeys(stored["interfaces"]); !reflect.DeepEqual(got, []string{"100"}) {
+				t.Errorf("interfaces = %v, want VLAN 100", stored["interfaces"])
+			}
+
+			got := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}}
+			if err := got.Get(sw); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			want_vrf := tt.vi.Vrf
+			if want_vrf == "" {
+				want_vrf = "default"
+			}
+			if got.Description != "SVI" || got.Vrf != want_vrf || !got.GetStatus() {
+				t.Errorf("Get() = %+v", got)
+			}
+			if len(tt.vi.Ipv4) > 0 && !reflect.DeepEqual(got.Ipv4, tt.vi.Ipv4) {
+				t.Errorf("Ipv4 = %v, want %v", got.Ipv4, tt.vi.Ipv4)
+			}
+			if !reflect.DeepEqual(sortedStrings(got.Ipv6), sortedStrings(tt.vi.Ipv6)) {
+				t.Errorf("Ipv6 = %v, want %v", got.Ipv6, tt.vi.Ipv6)
+			}
+		})
+	}
+}
+
+func TestVlanInterfaceUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   aoscxgo.VlanInterface
+		usePut   bool
+		wantErr  error
+		wantIpv6 []string
+	}{
+		{
+			name: "PATCH addresses",
+			update: aoscxgo.VlanInterface{
+				Description: "updated",
+				Ipv4:        []string{"10.100.9.1/24"},
+				Ipv6:        []string{"2001:db8:100::1/64", "2001:db8:101::1/64"},
+			},
+			wantIpv6: []string{"2001:db8:100::1/64", "2001:db8:101::1/64"},
+		},
+		{
+			name:     "PUT removing IPv6",
+			update:   aoscxgo.VlanInterface{Description: "updated", Ipv4: []string{"10.100.0.1/24"}},
+			usePut:   true,
+			wantIpv6: []string{},
+		},
+		{
+			name:    "invalid IPv4",
+			update:  aoscxgo.VlanInterface{Ipv4: []string{"10.100.0.1", "300.0.0.1"}},
+			wantErr: aoscxgo.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 100)
+
+			created := aoscxgo.VlanInterface{
+				Vlan: aoscxgo.Vlan{VlanId: 100, AdminState: "up"},
+				Ipv4: []string{"10.100.0.1/24"},
+				Ipv6: []string{"2001:db8:100::1/64"},
+			}
+			if err := created.Create(sw); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.update.Vlan = aoscxgo.Vlan{VlanId: 100, AdminState: "up"}
+			err := update(sw, &tt.update, tt.usePut)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got_ipv6 := srv.IP6Addresses("vlan100")
+			sort.Strings(got_ipv6)
+			if len(got_ipv6) != len(tt.wantIpv6) || (len(got_ipv6) > 0 && !reflect.DeepEqual(got_ipv6, tt.wantIpv6)) {
+				t.Errorf("ip6_addresses = %v, want %v", got_ipv6, tt.wantIpv6)
+			}
+
+			got := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}}
+			if err := got.Get(sw); err != nil {
+				t.Fatal(err)
+			}
+			if got.Description != tt.update.Description || !reflect.DeepEqual(got.Ipv4, tt.update.Ipv4) {
+				t.Errorf("Get() after Update = %+v", got)
+			}
+		})
+	}
+}
+
+func TestVlanInterfaceUpdateMissing(t *testing.T) {
+	_, sw := newTestSwitch(t)
+	createVlans(t, sw, 100)
+
+	vi := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}}
+	if err := vi.Replace(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Replace() on missing VLAN interface error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestVlanInterfaceDelete(t *testing.T) {
+	srv, sw := newTestSwitch(t)
+	createVlans(t, sw, 100)
+
+	vi := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: 100}, Ipv6: []string{"2001:db8:100::1/64"}}
+	if err := vi.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+	if err := vi.Delete(sw); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists := srv.Interface("vlan100"); exists {
+		t.Error("VLAN interface still exists after Delete")
+	}
+	if err := vi.Get(sw); !errors.Is(err, aoscxgo.ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if vi.GetStatus() {
+		t.Error("GetStatus() = true after Delete")
+	}
+
+	// The VLAN itself can be deleted once its interface is gone
+	vlan := aoscxgo.Vlan{VlanId: 100}
+	if err := vlan.Delete(sw); err != nil {
+		t.Errorf("Vlan.Delete() after VlanInterface.Delete error = %v", err)
+	}
+
+	missing := aoscxgo.VlanInterface{}
+	if err := missing.Delete(sw); !errors.Is(err, aoscxgo.ErrValidation) {
+		t.Errorf("Delete() without VLAN error = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,227 @@
+package aoscxgo_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/felixn-unity/aoscxgo"
+)
+
+func TestVlanCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vlan    aoscxgo.Vlan
+		wantErr error
+	}{
+		{
+			name: "all attributes",
+			vlan: aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "up"},
+		},
+		{
+			name: "required attributes only",
+			vlan: aoscxgo.Vlan{VlanId: 200, Name: "servers"},
+		},
+		{
+			name:    "missing name",
+			vlan:    aoscxgo.Vlan{VlanId: 300},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "missing id",
+			vlan:    aoscxgo.Vlan{Name: "no-id"},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "already exists",
+			vlan:    aoscxgo.Vlan{VlanId: 1, Name: "DEFAULT_VLAN_1"},
+			wantErr: aoscxgo.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+
+			err := tt.vlan.Create(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !tt.vlan.GetStatus() {
+				t.Error("GetStatus() = false after Create")
+			}
+			if want := "/rest/" + sw.Version + "/system/vlans/" + strconv.Itoa(tt.vlan.VlanId); tt.vlan.GetURI() != want {
+				t.Errorf("GetURI() = %q, want %q", tt.vlan.GetURI(), want)
+			}
+
+			stored, ok := srv.Vlan(tt.vlan.VlanId)
+			if !ok {
+				t.Fatal("VLAN not stored on switch")
+			}
+			if stored["name"] != tt.vlan.Name || stored["type"] != "static" {
+				t.Errorf("stored VLAN = %v", stored)
+			}
+			if tt.vlan.Description != "" && stored["description"] != tt.vlan.Description {
+				t.Errorf("stored description = %v, want %q", stored["description"], tt.vlan.Description)
+			}
+		})
+	}
+}
+
+func TestVlanGet(t *testing.T) {
+	_, sw := newTestSwitch(t)
+
+	created := aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "up"}
+	if err := created.Create(sw); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      int
+		want    aoscxgo.Vlan
+		wantErr error
+	}{
+		{
+			name: "existing",
+			id:   100,
+			want: aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "up"},
+		},
+		{
+			name: "default VLAN",
+			id:   1,
+			want: aoscxgo.Vlan{VlanId: 1, Name: "DEFAULT_VLAN_1", AdminState: "up"},
+		},
+		{
+			name:    "missing",
+			id:      999,
+			wantErr: aoscxgo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vlan := aoscxgo.Vlan{VlanId: tt.id}
+			err := vlan.Get(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if vlan.GetStatus() != (tt.wantErr == nil) {
+				t.Errorf("GetStatus() = %v", vlan.GetStatus())
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if vlan.Name != tt.want.Name || vlan.Description != tt.want.Description || vlan.AdminState != tt.want.AdminState {
+				t.Errorf("Get() = %+v, want %+v", vlan, tt.want)
+			}
+			if vlan.VlanDetails.ID != tt.id {
+				t.Errorf("VlanDetails.ID = %v", vlan.VlanDetails.ID)
+			}
+		})
+	}
+}
+
+func TestVlanUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  aoscxgo.Vlan
+		want    *aoscxgo.Vlan
+		wantErr error
+	}{
+		{
+			name:   "rename and describe",
+			update: aoscxgo.Vlan{VlanId: 100, Name: "renamed", Description: "new", AdminState: "down"},
+		},
+		{
+			name:   "keep description",
+			update: aoscxgo.Vlan{VlanId: 100, Name: "uplink", AdminState: "down"},
+			want:   &aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "down"},
+		},
+		{
+			name:   "clear description",
+			update: aoscxgo.Vlan{VlanId: 100, Name: "uplink", AdminState: "up", Mask: aoscxgo.FieldMask{"description"}},
+		},
+		{
+			name:    "missing name",
+			update:  aoscxgo.Vlan{VlanId: 100},
+			wantErr: aoscxgo.ErrValidation,
+		},
+		{
+			name:    "missing VLAN",
+			update:  aoscxgo.Vlan{VlanId: 999, Name: "ghost"},
+			wantErr: aoscxgo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sw := newTestSwitch(t)
+			created := aoscxgo.Vlan{VlanId: 100, Name: "uplink", Description: "to core", AdminState: "up"}
+			if err := created.Create(sw); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.update.Update(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := aoscxgo.Vlan{VlanId: tt.update.VlanId}
+			if err := got.Get(sw); err != nil {
+				t.Fatal(err)
+			}
+			want := &tt.update
+			if tt.want != nil {
+				want = tt.want
+			}
+			if got.Name != want.Name || got.Description != want.Description || got.AdminState != want.AdminState {
+				t.Errorf("after Update() Get = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestVlanDelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            int
+		vlanInterface bool
+		wantErr       error
+	}{
+		{name: "existing", id: 100},
+		{name: "missing is not an error", id: 999},
+		{name: "with VlanInterface", id: 100, vlanInterface: true, wantErr: aoscxgo.ErrDependency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sw := newTestSwitch(t)
+			createVlans(t, sw, 100)
+			if tt.vlanInterface {
+				vlan_int := aoscxgo.VlanInterface{Vlan: aoscxgo.Vlan{VlanId: tt.id}}
+				if err := vlan_int.Create(sw); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			vlan := aoscxgo.Vlan{VlanId: tt.id}
+			err := vlan.Delete(sw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, exists := srv.Vlan(tt.id)
+			if want := tt.wantErr != nil; exists != want {
+				t.Errorf("VLAN exists after Delete() = %v, want %v", exists, want)
+			}
+		})
+	}
+}